@@ -0,0 +1,150 @@
+package filter
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/bitrise-steplib/steps-nunit-runner/testresult"
+)
+
+// Test is a test case with its ancestor suites (outermost first), a filter is evaluated against.
+type Test struct {
+	TestCase  testresult.TestCase
+	Ancestors []testresult.TestSuite
+}
+
+// Expression is a parsed NUnit test selection expression.
+type Expression interface {
+	// Match reports whether the test is selected by the expression.
+	Match(test Test) bool
+}
+
+type orExpression struct {
+	left, right Expression
+}
+
+func (expression orExpression) Match(test Test) bool {
+	return expression.left.Match(test) || expression.right.Match(test)
+}
+
+type andExpression struct {
+	left, right Expression
+}
+
+func (expression andExpression) Match(test Test) bool {
+	return expression.left.Match(test) && expression.right.Match(test)
+}
+
+type notExpression struct {
+	operand Expression
+}
+
+func (expression notExpression) Match(test Test) bool {
+	return !expression.operand.Match(test)
+}
+
+// comparison compares a field of the test with a value. As in NUnit, a test matches the field of an ancestor suite as well
+// (for example test == MyApp.Tests.LoginTests selects every test of the fixture), and a negated comparison
+// matches if none of the field values match.
+type comparison struct {
+	field    string
+	operator string
+	value    string
+	regexp   *regexp.Regexp
+	negated  bool
+}
+
+func (expression comparison) Match(test Test) bool {
+	matched := false
+	for _, value := range fieldValues(test, expression.field) {
+		if expression.regexp != nil {
+			matched = expression.regexp.MatchString(value)
+		} else {
+			matched = value == expression.value
+		}
+		if matched {
+			break
+		}
+	}
+
+	if expression.negated {
+		return !matched
+	}
+	return matched
+}
+
+func fieldValues(test Test, field string) []string {
+	values := []string{}
+
+	switch field {
+	case "test":
+		values = append(values, test.TestCase.FullName)
+		for _, suite := range test.Ancestors {
+			values = append(values, suite.FullName)
+		}
+	case "name":
+		values = append(values, test.TestCase.Name)
+		for _, suite := range test.Ancestors {
+			values = append(values, suite.Name)
+		}
+	case "class":
+		values = append(values, test.TestCase.ClassName)
+	case "namespace":
+		if idx := strings.LastIndex(test.TestCase.ClassName, "."); idx > 0 {
+			values = append(values, test.TestCase.ClassName[:idx])
+		}
+	case "method":
+		values = append(values, test.TestCase.MethodName)
+	case "id":
+		values = append(values, test.TestCase.ID)
+		for _, suite := range test.Ancestors {
+			values = append(values, suite.ID)
+		}
+	case "cat":
+		values = append(values, test.TestCase.Categories()...)
+		for _, suite := range test.Ancestors {
+			values = append(values, suite.Categories()...)
+		}
+	default:
+		values = append(values, propertyValues(test.TestCase.Properties, field)...)
+		for _, suite := range test.Ancestors {
+			values = append(values, propertyValues(suite.Properties, field)...)
+		}
+	}
+
+	return values
+}
+
+func propertyValues(properties testresult.Properties, name string) []string {
+	values := []string{}
+	for _, property := range properties {
+		if property.Name == name {
+			values = append(values, property.Value)
+		}
+	}
+	return values
+}
+
+// SelectTestCases returns the test cases of the run, which are selected by the expression, in document order.
+func SelectTestCases(run testresult.TestRun, expression Expression) []testresult.TestCase {
+	selected := []testresult.TestCase{}
+	for _, suite := range run.TestSuites {
+		selected = append(selected, selectTestCases(suite, nil, expression)...)
+	}
+	return selected
+}
+
+func selectTestCases(suite testresult.TestSuite, ancestors []testresult.TestSuite, expression Expression) []testresult.TestCase {
+	ancestors = append(append([]testresult.TestSuite{}, ancestors...), suite)
+
+	selected := []testresult.TestCase{}
+	for _, testCase := range suite.TestCases {
+		if expression.Match(Test{TestCase: testCase, Ancestors: ancestors}) {
+			selected = append(selected, testCase)
+		}
+	}
+	for _, child := range suite.TestSuites {
+		selected = append(selected, selectTestCases(child, ancestors, expression)...)
+	}
+	return selected
+}
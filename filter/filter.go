@@ -0,0 +1,146 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Model describes the test selection of the step inputs.
+type Model struct {
+	// Where is an NUnit test selection expression, like: cat == Integration and method =~ Login
+	Where string
+	// IncludeCategories selects the tests, which have any of the categories
+	IncludeCategories []string
+	// ExcludeCategories deselects the tests, which have any of the categories
+	ExcludeCategories []string
+	// TestNamePatterns selects the tests, which fully qualified name matches any of the patterns,
+	// where * matches any sequence of characters and ? matches a single character
+	TestNamePatterns []string
+}
+
+// IsEmpty reports whether the model selects every test.
+func (model Model) IsEmpty() bool {
+	return strings.TrimSpace(model.Where) == "" &&
+		len(model.IncludeCategories) == 0 &&
+		len(model.ExcludeCategories) == 0 &&
+		len(model.TestNamePatterns) == 0
+}
+
+// Validate ...
+func (model Model) Validate() error {
+	if strings.TrimSpace(model.Where) != "" {
+		if _, err := Parse(model.Where); err != nil {
+			return fmt.Errorf("invalid test filter (%s), error: %s", model.Where, err)
+		}
+	}
+
+	for _, category := range append(append([]string{}, model.IncludeCategories...), model.ExcludeCategories...) {
+		if category == "" {
+			return fmt.Errorf("empty category")
+		}
+	}
+
+	for _, pattern := range model.TestNamePatterns {
+		if pattern == "" {
+			return fmt.Errorf("empty test name pattern")
+		}
+	}
+
+	return nil
+}
+
+// Expression combines the selection into a single NUnit test selection expression,
+// to pass with the --where option of nunit3-console. It returns an empty string if the model selects every test.
+func (model Model) Expression() string {
+	expressions := []string{}
+
+	if where := strings.TrimSpace(model.Where); where != "" {
+		expressions = append(expressions, where)
+	}
+
+	if len(model.IncludeCategories) > 0 {
+		terms := []string{}
+		for _, category := range model.IncludeCategories {
+			terms = append(terms, "cat == "+Quote(category))
+		}
+		expressions = append(expressions, strings.Join(terms, " or "))
+	}
+
+	if len(model.ExcludeCategories) > 0 {
+		terms := []string{}
+		for _, category := range model.ExcludeCategories {
+			terms = append(terms, "cat != "+Quote(category))
+		}
+		expressions = append(expressions, strings.Join(terms, " and "))
+	}
+
+	if len(model.TestNamePatterns) > 0 {
+		terms := []string{}
+		for _, pattern := range model.TestNamePatterns {
+			if strings.ContainsAny(pattern, "*?") {
+				terms = append(terms, "test =~ "+Quote(GlobToRegexp(pattern)))
+			} else {
+				terms = append(terms, "test == "+Quote(pattern))
+			}
+		}
+		expressions = append(expressions, strings.Join(terms, " or "))
+	}
+
+	if len(expressions) == 1 {
+		return expressions[0]
+	}
+
+	for i, expression := range expressions {
+		expressions[i] = "(" + expression + ")"
+	}
+	return strings.Join(expressions, " and ")
+}
+
+// Parse returns the parsed Expression of the model, or nil if the model selects every test.
+func (model Model) Parse() (Expression, error) {
+	if model.IsEmpty() {
+		return nil, nil
+	}
+	return Parse(model.Expression())
+}
+
+// SplitList splits a step input list, by new lines and by the given separators, dropping the empty items.
+func SplitList(list string, separators ...string) []string {
+	for _, separator := range separators {
+		list = strings.Replace(list, separator, "\n", -1)
+	}
+
+	items := []string{}
+	for _, item := range strings.Split(list, "\n") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Quote returns the value as a quoted string of the test selection language,
+// where the quote and the backslash characters are escaped by a backslash.
+func Quote(value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, `"`, `\"`, -1)
+	return `"` + value + `"`
+}
+
+// GlobToRegexp converts a test name pattern to an anchored regular expression:
+// * matches any sequence of characters (including / and .), ? matches a single character, the rest match literally.
+func GlobToRegexp(pattern string) string {
+	expression := "^"
+	for _, c := range pattern {
+		switch c {
+		case '*':
+			expression += ".*"
+		case '?':
+			expression += "."
+		default:
+			expression += regexp.QuoteMeta(string(c))
+		}
+	}
+	return expression + "$"
+}
@@ -0,0 +1,67 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bitrise-steplib/steps-nunit-runner/testresult"
+)
+
+func TestExpression(t *testing.T) {
+	for _, tc := range []struct {
+		model    Model
+		expected string
+	}{
+		{Model{}, ""},
+		{Model{Where: " cat == UI "}, "cat == UI"},
+		{Model{IncludeCategories: []string{"UI", "Smoke"}}, `cat == "UI" or cat == "Smoke"`},
+		{Model{ExcludeCategories: []string{"Slow", "Flaky"}}, `cat != "Slow" and cat != "Flaky"`},
+		{Model{TestNamePatterns: []string{"MyApp.Tests.Login*", "MyApp.Tests.Add"}}, `test =~ "^MyApp\\.Tests\\.Login.*$" or test == "MyApp.Tests.Add"`},
+		{Model{Where: "method =~ Login", ExcludeCategories: []string{`Sl"ow`}}, `(method =~ Login) and (cat != "Sl\"ow")`},
+	} {
+		if expression := tc.model.Expression(); expression != tc.expected {
+			t.Errorf("%+v: expected: %s, got: %s", tc.model, tc.expected, expression)
+		}
+		if err := tc.model.Validate(); err != nil {
+			t.Errorf("%+v: unexpected error: %s", tc.model, err)
+		}
+	}
+}
+
+func TestSelectTestCases(t *testing.T) {
+	run := testresult.TestRun{
+		TestSuites: []testresult.TestSuite{
+			{
+				Type: "TestFixture", Name: "LoginTests", FullName: "MyApp.Tests.LoginTests",
+				Properties: testresult.Properties{{Name: "Category", Value: "UI"}},
+				TestCases: []testresult.TestCase{
+					{Name: "ValidLogin", FullName: "MyApp.Tests.LoginTests.ValidLogin", Result: testresult.ResultFailed},
+					{Name: "Logout", FullName: "MyApp.Tests.LoginTests.Logout", Result: testresult.ResultPassed},
+				},
+			},
+			{
+				Type: "TestFixture", Name: "CalculatorTests", FullName: "MyApp.Tests.CalculatorTests",
+				TestCases: []testresult.TestCase{
+					{Name: "Add", FullName: "MyApp.Tests.CalculatorTests.Add", Result: testresult.ResultFailed},
+				},
+			},
+		},
+	}
+
+	expression, err := Model{IncludeCategories: []string{"UI"}, TestNamePatterns: []string{"*Login"}}.Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	names := []string{}
+	for _, testCase := range SelectTestCases(run, expression) {
+		names = append(names, testCase.FullName)
+	}
+	if expected := []string{"MyApp.Tests.LoginTests.ValidLogin"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected: %v, got: %v", expected, names)
+	}
+
+	if expression, err := (Model{}).Parse(); err != nil || expression != nil {
+		t.Fatalf("expected no expression for an empty model, got: %v, %v", expression, err)
+	}
+}
@@ -0,0 +1,268 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// The grammar of the NUnit test selection language:
+//
+//	expression := term { ( "or" | "||" ) term }
+//	term       := factor { ( "and" | "&&" ) factor }
+//	factor     := ( "not" | "!" ) factor | "(" expression ")" | field operator value
+//	field      := "cat" | "test" | "name" | "class" | "namespace" | "method" | "id" | property name
+//	operator   := "==" | "=" | "!=" | "=~" | "!~"
+//	value      := word | "string" | 'string' | /string/
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenSymbol
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	start int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of filter"
+	}
+	return fmt.Sprintf("'%s' at position %d", t.text, t.start+1)
+}
+
+// wordBreakChars end a word, without surrounding whitespace
+const wordBreakChars = "=!()&|"
+
+var symbols = []string{"==", "=~", "!=", "!~", "&&", "||", "=", "!", "(", ")"}
+
+func tokenize(where string) ([]token, error) {
+	tokens := []token{}
+	runes := []rune(where)
+
+	for i := 0; i < len(runes); {
+		c := runes[i]
+
+		if unicode.IsSpace(c) {
+			i++
+			continue
+		}
+
+		if c == '"' || c == '\'' || c == '/' {
+			start := i
+			value := []rune{}
+			closed := false
+
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && (runes[i+1] == c || runes[i+1] == '\\') {
+					i++
+					value = append(value, runes[i])
+					continue
+				}
+				if runes[i] == c {
+					closed = true
+					i++
+					break
+				}
+				value = append(value, runes[i])
+			}
+
+			if !closed {
+				return nil, fmt.Errorf("unterminated string at position %d", start+1)
+			}
+
+			tokens = append(tokens, token{kind: tokenString, text: string(value), start: start})
+			continue
+		}
+
+		if symbol := matchSymbol(runes[i:]); symbol != "" {
+			tokens = append(tokens, token{kind: tokenSymbol, text: symbol, start: i})
+			i += len(symbol)
+			continue
+		}
+
+		start := i
+		for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune(wordBreakChars, runes[i]) {
+			i++
+		}
+		tokens = append(tokens, token{kind: tokenWord, text: string(runes[start:i]), start: start})
+	}
+
+	return append(tokens, token{kind: tokenEOF, start: len(runes)}), nil
+}
+
+func matchSymbol(runes []rune) string {
+	for _, symbol := range symbols {
+		if strings.HasPrefix(string(runes), symbol) {
+			return symbol
+		}
+	}
+	return ""
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Parse parses an NUnit test selection expression, like: cat == Integration and method =~ Login
+func Parse(where string) (Expression, error) {
+	tokens, err := tokenize(where)
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser{tokens: tokens}
+	expression, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	if next := p.peek(); next.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %s", next)
+	}
+
+	return expression, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) accept(texts ...string) bool {
+	t := p.peek()
+	if t.kind != tokenSymbol && t.kind != tokenWord {
+		return false
+	}
+	for _, text := range texts {
+		if t.text == text {
+			p.pos++
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parseExpression() (Expression, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("or", "||") {
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpression{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseTerm() (Expression, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("and", "&&") {
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpression{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseFactor() (Expression, error) {
+	if p.accept("not", "!") {
+		operand, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return notExpression{operand}, nil
+	}
+
+	if p.accept("(") {
+		expression, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("expected ')', got %s", p.peek())
+		}
+		return expression, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Expression, error) {
+	fieldToken := p.next()
+	if fieldToken.kind != tokenWord || isKeyword(fieldToken.text) {
+		return nil, fmt.Errorf("expected a field name (cat, test, name, class, namespace, method, id or a property name), got %s", fieldToken)
+	}
+
+	operatorToken := p.next()
+	if operatorToken.kind != tokenSymbol {
+		return nil, fmt.Errorf("expected an operator (==, !=, =~ or !~) after %s, got %s", fieldToken.text, operatorToken)
+	}
+
+	comparison := comparison{field: fieldToken.text}
+	switch operatorToken.text {
+	case "==", "=":
+		comparison.operator = "=="
+	case "!=":
+		comparison.operator = "!="
+		comparison.negated = true
+	case "=~":
+		comparison.operator = "=~"
+	case "!~":
+		comparison.operator = "!~"
+		comparison.negated = true
+	default:
+		return nil, fmt.Errorf("expected an operator (==, !=, =~ or !~) after %s, got %s", fieldToken.text, operatorToken)
+	}
+
+	valueToken := p.next()
+	if valueToken.kind != tokenWord && valueToken.kind != tokenString {
+		return nil, fmt.Errorf("expected a value after %s %s, got %s", fieldToken.text, operatorToken.text, valueToken)
+	}
+	comparison.value = valueToken.text
+
+	if comparison.operator == "=~" || comparison.operator == "!~" {
+		if comparison.field == "id" {
+			return nil, fmt.Errorf("the id field does not support regular expressions, at position %d", operatorToken.start+1)
+		}
+
+		re, err := regexp.Compile(comparison.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression (%s), error: %s", comparison.value, err)
+		}
+		comparison.regexp = re
+	}
+
+	return comparison, nil
+}
+
+func isKeyword(text string) bool {
+	return text == "and" || text == "or" || text == "not"
+}
@@ -0,0 +1,127 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bitrise-steplib/steps-nunit-runner/testresult"
+)
+
+func testCase(fullName string, categories ...string) Test {
+	className, name := "", fullName
+	if idx := strings.LastIndex(fullName, "."); idx > -1 {
+		className, name = fullName[:idx], fullName[idx+1:]
+	}
+
+	properties := testresult.Properties{}
+	for _, category := range categories {
+		properties = append(properties, testresult.Property{Name: "Category", Value: category})
+	}
+
+	return Test{
+		TestCase: testresult.TestCase{
+			ID:         "0-1001",
+			Name:       name,
+			FullName:   fullName,
+			MethodName: name,
+			ClassName:  className,
+			Properties: properties,
+		},
+		Ancestors: []testresult.TestSuite{
+			{Type: "TestFixture", ID: "0-1000", Name: className[strings.LastIndex(className, ".")+1:], FullName: className},
+		},
+	}
+}
+
+func TestParse(t *testing.T) {
+	login := testCase("MyApp.Tests.LoginTests.ValidLogin", "UI", "Slow")
+	logout := testCase("MyApp.Tests.LoginTests.Logout", "UI")
+	add := testCase("MyApp.Core.CalculatorTests.Add")
+	quoted := testCase(`MyApp.Tests.NameTests.Say("hi \ there")`)
+
+	for _, tc := range []struct {
+		where    string
+		test     Test
+		expected bool
+	}{
+		// fields
+		{"cat == UI", login, true},
+		{"cat == UI", add, false},
+		{"cat != UI", add, true},
+		{"cat != Slow", logout, true},
+		{"cat != Slow", login, false},
+		{"test == MyApp.Tests.LoginTests.Logout", logout, true},
+		{"test == MyApp.Tests.LoginTests", logout, true},
+		{"name == Add", add, true},
+		{"name == CalculatorTests", add, true},
+		{"class == MyApp.Core.CalculatorTests", add, true},
+		{"namespace == MyApp.Core", add, true},
+		{"namespace == MyApp", add, false},
+		{"method == ValidLogin", login, true},
+		{"id == 0-1001", add, true},
+		{"id == 0-1000", add, true},
+		{"id = 0-1002", add, false},
+
+		// regular expressions
+		{"method =~ Log", login, true},
+		{"method =~ ^Log", login, false},
+		{"method !~ Log", add, true},
+		{"test =~ /Login.*Valid/", login, true},
+		{`test =~ "\.Core\."`, add, true},
+		{"class !~ Login", logout, false},
+
+		// operator precedence: not > and > or
+		{"cat == UI or cat == Slow and method == Add", logout, true},
+		{"(cat == UI or cat == Slow) and method == Add", logout, false},
+		{"cat == Slow or method == Logout and cat == UI", logout, true},
+		{"not cat == Slow and cat == UI", logout, true},
+		{"not (cat == Slow and cat == UI)", login, false},
+		{"!cat == Slow && cat == UI || method == Add", add, true},
+		{"cat == UI and not method == Logout", login, true},
+		{"cat == UI and not method == Logout", logout, false},
+
+		// quoting
+		{`test == "MyApp.Tests.LoginTests.ValidLogin"`, login, true},
+		{`test == 'MyApp.Tests.LoginTests.ValidLogin'`, login, true},
+		{`test == "MyApp.Tests.NameTests.Say(\"hi \\ there\")"`, quoted, true},
+		{`method=="Logout"&&cat==UI`, logout, true},
+	} {
+		expression, err := Parse(tc.where)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.where, err)
+			continue
+		}
+		if matched := expression.Match(tc.test); matched != tc.expected {
+			t.Errorf("%s: expected match of %s: %v, got: %v", tc.where, tc.test.TestCase.FullName, tc.expected, matched)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		where string
+		err   string
+	}{
+		{"", "expected a field name"},
+		{"cat", "expected an operator"},
+		{"cat ==", "expected a value"},
+		{"cat == UI and", "expected a field name"},
+		{"cat == UI or or cat == Slow", "expected a field name"},
+		{"and == UI", "expected a field name"},
+		{"cat UI", "expected an operator"},
+		{"(cat == UI", "expected ')'"},
+		{"cat == UI)", "unexpected ')'"},
+		{"cat == UI cat == Slow", "unexpected 'cat' at position 11"},
+		{`test == "MyApp`, "unterminated string at position 9"},
+		{"method =~ /Log(/", "invalid regular expression"},
+		{"id =~ 0-1", "the id field does not support regular expressions"},
+		{"cat ! UI", "expected an operator"},
+	} {
+		_, err := Parse(tc.where)
+		if err == nil {
+			t.Errorf("%s: expected an error", tc.where)
+		} else if !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: expected error containing: %s, got: %s", tc.where, tc.err, err)
+		}
+	}
+}
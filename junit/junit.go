@@ -0,0 +1,251 @@
+package junit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-steplib/steps-nunit-runner/testresult"
+)
+
+// TestSuites ...
+type TestSuites struct {
+	XMLName xml.Name `xml:"testsuites"`
+
+	Name     string  `xml:"name,attr,omitempty"`
+	Tests    int     `xml:"tests,attr"`
+	Failures int     `xml:"failures,attr"`
+	Errors   int     `xml:"errors,attr"`
+	Skipped  int     `xml:"skipped,attr"`
+	Time     float64 `xml:"time,attr"`
+
+	TestSuites []TestSuite `xml:"testsuite"`
+}
+
+// TestSuite ...
+type TestSuite struct {
+	Name      string  `xml:"name,attr"`
+	Tests     int     `xml:"tests,attr"`
+	Failures  int     `xml:"failures,attr"`
+	Errors    int     `xml:"errors,attr"`
+	Skipped   int     `xml:"skipped,attr"`
+	Time      float64 `xml:"time,attr"`
+	Timestamp string  `xml:"timestamp,attr,omitempty"`
+
+	Properties *Properties `xml:"properties"`
+	TestCases  []TestCase  `xml:"testcase"`
+}
+
+// Properties ...
+type Properties struct {
+	Properties []Property `xml:"property"`
+}
+
+// Property ...
+type Property struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// TestCase ...
+type TestCase struct {
+	Name      string  `xml:"name,attr"`
+	ClassName string  `xml:"classname,attr"`
+	Time      float64 `xml:"time,attr"`
+
+	Failure   *Failure `xml:"failure"`
+	Error     *Failure `xml:"error"`
+	Skipped   *Skipped `xml:"skipped"`
+	SystemOut string   `xml:"system-out,omitempty"`
+}
+
+// Failure ...
+type Failure struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",cdata"`
+}
+
+// Skipped ...
+type Skipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// Convert converts a NUnit test run into JUnit test suites.
+// Every NUnit suite, which directly contains test cases (fixtures), becomes a JUnit test suite.
+// The cases of parameterized methods and theories are attached to the suite of their fixture.
+func Convert(run testresult.TestRun) TestSuites {
+	testSuites := TestSuites{TestSuites: []TestSuite{}}
+
+	for _, suite := range run.TestSuites {
+		testSuites.TestSuites = append(testSuites.TestSuites, convertTestSuite(suite)...)
+	}
+
+	for _, suite := range testSuites.TestSuites {
+		testSuites.Tests += suite.Tests
+		testSuites.Failures += suite.Failures
+		testSuites.Errors += suite.Errors
+		testSuites.Skipped += suite.Skipped
+		testSuites.Time += suite.Time
+	}
+
+	return testSuites
+}
+
+// WriteFile ...
+func (testSuites TestSuites) WriteFile(pth string) error {
+	content, err := xml.MarshalIndent(testSuites, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to serialize junit test result, error: %s", err)
+	}
+
+	return fileutil.WriteStringToFile(pth, xml.Header+string(content))
+}
+
+func isMethodSuite(suite testresult.TestSuite) bool {
+	return suite.Type == "ParameterizedMethod" || suite.Type == "Theory" || suite.Type == "GenericMethod"
+}
+
+func methodLevelTestCases(suite testresult.TestSuite) []testresult.TestCase {
+	testCases := append([]testresult.TestCase{}, suite.TestCases...)
+	for _, child := range suite.TestSuites {
+		if isMethodSuite(child) {
+			testCases = append(testCases, child.AllTestCases()...)
+		}
+	}
+	return testCases
+}
+
+func convertTestSuite(suite testresult.TestSuite) []TestSuite {
+	testSuites := []TestSuite{}
+
+	if testCases := methodLevelTestCases(suite); len(testCases) > 0 {
+		testSuite := TestSuite{
+			Name:      suite.FullName,
+			Timestamp: convertTime(suite.StartTime),
+			TestCases: []TestCase{},
+		}
+
+		if len(suite.Properties) > 0 {
+			testSuite.Properties = &Properties{}
+			for _, property := range suite.Properties {
+				testSuite.Properties.Properties = append(testSuite.Properties.Properties, Property{Name: property.Name, Value: property.Value})
+			}
+		}
+
+		for _, testCase := range testCases {
+			converted := convertTestCase(testCase, suite.FullName)
+
+			testSuite.Tests++
+			testSuite.Time += converted.Time
+			if converted.Failure != nil {
+				testSuite.Failures++
+			}
+			if converted.Error != nil {
+				testSuite.Errors++
+			}
+			if converted.Skipped != nil {
+				testSuite.Skipped++
+			}
+
+			testSuite.TestCases = append(testSuite.TestCases, converted)
+		}
+
+		testSuites = append(testSuites, testSuite)
+	}
+
+	for _, child := range suite.TestSuites {
+		if !isMethodSuite(child) {
+			testSuites = append(testSuites, convertTestSuite(child)...)
+		}
+	}
+
+	return testSuites
+}
+
+func convertTestCase(testCase testresult.TestCase, suiteFullName string) TestCase {
+	className := testCase.ClassName
+	if className == "" {
+		className = suiteFullName
+	}
+
+	converted := TestCase{
+		Name:      testCase.Name,
+		ClassName: className,
+		Time:      testCase.Duration,
+	}
+
+	output := []string{}
+	if testCase.Output != "" {
+		output = append(output, strings.TrimSpace(string(testCase.Output)))
+	}
+
+	switch testCase.Result {
+	case testresult.ResultFailed:
+		failure := &Failure{}
+		if testCase.Failure != nil {
+			failure.Message = firstLine(testCase.Failure.Message)
+			failure.Text = strings.TrimSpace(strings.TrimSpace(testCase.Failure.Message) + "\n" + strings.TrimSpace(testCase.Failure.StackTrace))
+		}
+
+		if testCase.IsError() {
+			failure.Type = exceptionType(testCase)
+			converted.Error = failure
+		} else {
+			failure.Type = "Assertion"
+			converted.Failure = failure
+		}
+	case testresult.ResultSkipped, testresult.ResultInconclusive:
+		skipped := &Skipped{}
+		if testCase.Reason != nil {
+			skipped.Message = firstLine(testCase.Reason.Message)
+		}
+		if skipped.Message == "" {
+			skipped.Message = strings.TrimSpace(testCase.Result + " " + testCase.Label)
+		}
+		converted.Skipped = skipped
+	case testresult.ResultWarning:
+		if testCase.Reason != nil {
+			output = append(output, "Warning: "+strings.TrimSpace(testCase.Reason.Message))
+		}
+	}
+
+	for _, attachment := range testCase.Attachments {
+		output = append(output, fmt.Sprintf("[[ATTACHMENT|%s]]", attachment.FilePath))
+	}
+
+	converted.SystemOut = strings.Join(output, "\n")
+
+	return converted
+}
+
+// exceptionType returns the exception type of an errored test case,
+// NUnit writes error messages like: System.DivideByZeroException : Attempted to divide by zero.
+func exceptionType(testCase testresult.TestCase) string {
+	if testCase.Label != testresult.LabelError || testCase.Failure == nil {
+		return testCase.Label
+	}
+
+	message := strings.TrimSpace(testCase.Failure.Message)
+	if idx := strings.Index(message, " : "); idx > 0 && !strings.Contains(message[:idx], " ") {
+		return message[:idx]
+	}
+	return testCase.Label
+}
+
+func firstLine(message string) string {
+	message = strings.TrimSpace(message)
+	if idx := strings.Index(message, "\n"); idx > -1 {
+		return strings.TrimSpace(message[:idx])
+	}
+	return message
+}
+
+// convertTime converts NUnit times (2017-09-01 10:00:00Z) to the ISO 8601 format used by JUnit (2017-09-01T10:00:00).
+func convertTime(nunitTime string) string {
+	if nunitTime == "" {
+		return ""
+	}
+	return strings.TrimSuffix(strings.Replace(nunitTime, " ", "T", 1), "Z")
+}
@@ -4,13 +4,22 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/bitrise-io/go-utils/fileutil"
 	"github.com/bitrise-io/go-utils/log"
 	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-steplib/steps-nunit-runner/filter"
+	"github.com/bitrise-steplib/steps-nunit-runner/junit"
+	"github.com/bitrise-steplib/steps-nunit-runner/outcome"
+	"github.com/bitrise-steplib/steps-nunit-runner/quarantine"
+	"github.com/bitrise-steplib/steps-nunit-runner/report"
+	"github.com/bitrise-steplib/steps-nunit-runner/testresult"
+	"github.com/bitrise-steplib/steps-nunit-runner/testrunner"
+	"github.com/bitrise-steplib/steps-nunit-runner/toolchain"
 	"github.com/bitrise-tools/go-steputils/input"
 	steptools "github.com/bitrise-tools/go-steputils/tools"
-	"github.com/bitrise-tools/go-xamarin/builder"
 	"github.com/bitrise-tools/go-xamarin/constants"
 	"github.com/bitrise-tools/go-xamarin/tools"
 	"github.com/bitrise-tools/go-xamarin/tools/buildtools"
@@ -22,12 +31,52 @@ type ConfigsModel struct {
 	XamarinSolution      string
 	XamarinConfiguration string
 	XamarinPlatform      string
-
-	CustomOptions string
+	TestAssemblies       string
+
+	NunitConsolePath  string
+	Nunit2ConsolePath string
+	CustomOptions     string
+	Nunit2Options     string
+	NunitLiteOptions  string
+	RetryCount        string
+
+	TestRunner        string
+	TestInput         string
+	SingleInvocation  string
+	DotnetTestLogger  string
+	DotnetTestOptions string
+
+	TestFilter        string
+	IncludeCategories string
+	ExcludeCategories string
+	TestNamePatterns  string
+
+	IncludeProjects string
+	ExcludeProjects string
+	ProjectOrder    string
+	ProjectPriority string
+	Concurrency     string
+
+	ShardIndex       string
+	ShardCount       string
+	ShardBy          string
+	ShardTimingsPath string
+
+	FailOnMissingResults  string
+	FailOnNoTestsExecuted string
+	FailOnTestFailures    string
+	QuarantineFile        string
 
 	BuildTool      string
 	BuildBeforeRun string
+	BuildScope     string
+	DryRun         string
 	DeployDir      string
+
+	MonoPath    string
+	MsbuildPath string
+	XbuildPath  string
+	DotnetPath  string
 }
 
 func createConfigsModelFromEnvs() ConfigsModel {
@@ -35,12 +84,52 @@ func createConfigsModelFromEnvs() ConfigsModel {
 		XamarinSolution:      os.Getenv("xamarin_solution"),
 		XamarinConfiguration: os.Getenv("xamarin_configuration"),
 		XamarinPlatform:      os.Getenv("xamarin_platform"),
-
-		CustomOptions: os.Getenv("nunit_options"),
+		TestAssemblies:       os.Getenv("test_assemblies"),
+
+		NunitConsolePath:  os.Getenv("nunit_console_path"),
+		Nunit2ConsolePath: os.Getenv("nunit2_console_path"),
+		CustomOptions:     os.Getenv("nunit_options"),
+		Nunit2Options:     os.Getenv("nunit2_options"),
+		NunitLiteOptions:  os.Getenv("nunitlite_options"),
+		RetryCount:        os.Getenv("retry_failed_tests"),
+
+		TestRunner:        os.Getenv("test_runner"),
+		TestInput:         os.Getenv("test_input"),
+		SingleInvocation:  os.Getenv("single_console_invocation"),
+		DotnetTestLogger:  os.Getenv("dotnet_test_logger"),
+		DotnetTestOptions: os.Getenv("dotnet_test_options"),
+
+		TestFilter:        os.Getenv("test_filter"),
+		IncludeCategories: os.Getenv("include_categories"),
+		ExcludeCategories: os.Getenv("exclude_categories"),
+		TestNamePatterns:  os.Getenv("test_name_patterns"),
+
+		IncludeProjects: os.Getenv("include_test_projects"),
+		ExcludeProjects: os.Getenv("exclude_test_projects"),
+		ProjectOrder:    os.Getenv("test_project_order"),
+		ProjectPriority: os.Getenv("test_project_priority"),
+		Concurrency:     os.Getenv("test_project_concurrency"),
+
+		ShardIndex:       os.Getenv("shard_index"),
+		ShardCount:       os.Getenv("shard_count"),
+		ShardBy:          os.Getenv("shard_by"),
+		ShardTimingsPath: os.Getenv("shard_timings_path"),
+
+		FailOnMissingResults:  os.Getenv("fail_on_missing_results"),
+		FailOnNoTestsExecuted: os.Getenv("fail_on_no_tests_executed"),
+		FailOnTestFailures:    os.Getenv("fail_on_test_failures"),
+		QuarantineFile:        os.Getenv("quarantine_file"),
 
 		BuildTool:      os.Getenv("build_tool"),
 		BuildBeforeRun: os.Getenv("build_before_test"),
+		BuildScope:     os.Getenv("build_scope"),
+		DryRun:         os.Getenv("dry_run"),
 		DeployDir:      os.Getenv("BITRISE_DEPLOY_DIR"),
+
+		MonoPath:    os.Getenv("mono_path"),
+		MsbuildPath: os.Getenv("msbuild_path"),
+		XbuildPath:  os.Getenv("xbuild_path"),
+		DotnetPath:  os.Getenv("dotnet_path"),
 	}
 }
 
@@ -50,32 +139,281 @@ func (configs ConfigsModel) print() {
 	log.Printf("- XamarinSolution: %s", configs.XamarinSolution)
 	log.Printf("- XamarinConfiguration: %s", configs.XamarinConfiguration)
 	log.Printf("- XamarinPlatform: %s", configs.XamarinPlatform)
+	log.Printf("- TestAssemblies: %s", configs.TestAssemblies)
+	log.Printf("- NunitConsolePath: %s", configs.NunitConsolePath)
+	log.Printf("- Nunit2ConsolePath: %s", configs.Nunit2ConsolePath)
+	log.Printf("- RetryCount: %s", configs.RetryCount)
+	log.Printf("- TestRunner: %s", configs.TestRunner)
+	log.Printf("- TestInput: %s", configs.TestInput)
+	log.Printf("- SingleInvocation: %s", configs.SingleInvocation)
+	log.Printf("- DotnetTestLogger: %s", configs.DotnetTestLogger)
+
+	log.Infof("Test selection:")
+
+	log.Printf("- TestFilter: %s", configs.TestFilter)
+	log.Printf("- IncludeCategories: %s", configs.IncludeCategories)
+	log.Printf("- ExcludeCategories: %s", configs.ExcludeCategories)
+	log.Printf("- TestNamePatterns: %s", configs.TestNamePatterns)
+	log.Printf("- IncludeProjects: %s", configs.IncludeProjects)
+	log.Printf("- ExcludeProjects: %s", configs.ExcludeProjects)
+	log.Printf("- ProjectOrder: %s", configs.ProjectOrder)
+	log.Printf("- ProjectPriority: %s", configs.ProjectPriority)
+	log.Printf("- Concurrency: %s", configs.Concurrency)
+
+	log.Infof("Sharding:")
+
+	log.Printf("- ShardIndex: %s", configs.ShardIndex)
+	log.Printf("- ShardCount: %s", configs.ShardCount)
+	log.Printf("- ShardBy: %s", configs.ShardBy)
+	log.Printf("- ShardTimingsPath: %s", configs.ShardTimingsPath)
+
+	log.Infof("Result policy:")
+
+	log.Printf("- FailOnMissingResults: %s", configs.FailOnMissingResults)
+	log.Printf("- FailOnNoTestsExecuted: %s", configs.FailOnNoTestsExecuted)
+	log.Printf("- FailOnTestFailures: %s", configs.FailOnTestFailures)
+	log.Printf("- QuarantineFile: %s", configs.QuarantineFile)
 
 	log.Infof("Debug:")
 
 	log.Printf("- BuildBeforeTest: %s", configs.BuildBeforeRun)
+	log.Printf("- BuildScope: %s", configs.BuildScope)
 	log.Printf("- CustomOptions: %s", configs.CustomOptions)
+	log.Printf("- Nunit2Options: %s", configs.Nunit2Options)
+	log.Printf("- NunitLiteOptions: %s", configs.NunitLiteOptions)
+	log.Printf("- DotnetTestOptions: %s", configs.DotnetTestOptions)
 	log.Printf("- BuildTool: %s", configs.BuildTool)
+	log.Printf("- DryRun: %s", configs.DryRun)
 	log.Printf("- DeployDir: %s", configs.DeployDir)
+	log.Printf("- MonoPath: %s", configs.MonoPath)
+	log.Printf("- MsbuildPath: %s", configs.MsbuildPath)
+	log.Printf("- XbuildPath: %s", configs.XbuildPath)
+	log.Printf("- DotnetPath: %s", configs.DotnetPath)
 }
 
 func (configs ConfigsModel) validate() error {
-	if err := input.ValidateIfPathExists(configs.XamarinSolution); err != nil {
-		return fmt.Errorf("XamarinSolution - %s", err)
+	// the test assemblies run without a solution
+	if configs.TestAssemblies == "" {
+		if err := input.ValidateIfPathExists(configs.XamarinSolution); err != nil {
+			return fmt.Errorf("XamarinSolution - %s", err)
+		}
+		if err := input.ValidateIfNotEmpty(configs.XamarinConfiguration); err != nil {
+			return fmt.Errorf("XamarinConfiguration - %s", err)
+		}
+		if err := input.ValidateIfNotEmpty(configs.XamarinPlatform); err != nil {
+			return fmt.Errorf("XamarinPlatform - %s", err)
+		}
+	}
+
+	if retryCount, err := strconv.Atoi(configs.RetryCount); err != nil || retryCount < 0 {
+		return fmt.Errorf("RetryCount - should be a non-negative number, got: %s", configs.RetryCount)
+	}
+
+	if err := input.ValidateWithOptions(configs.TestRunner, string(testrunner.TestRunnerAuto), string(testrunner.TestRunnerNunitConsole), string(testrunner.TestRunnerDotnetTest)); err != nil {
+		return fmt.Errorf("TestRunner - %s", err)
+	}
+	if err := input.ValidateWithOptions(configs.TestInput, string(testrunner.TestInputProject), string(testrunner.TestInputAssembly)); err != nil {
+		return fmt.Errorf("TestInput - %s", err)
+	}
+	if err := input.ValidateWithOptions(configs.SingleInvocation, "true", "false"); err != nil {
+		return fmt.Errorf("SingleInvocation - %s", err)
+	}
+	if configs.SingleInvocation == "true" && configs.TestInput != string(testrunner.TestInputAssembly) && configs.TestAssemblies == "" {
+		return fmt.Errorf("SingleInvocation - the test assemblies run in one console invocation only if the test input is: %s", testrunner.TestInputAssembly)
+	}
+	if err := input.ValidateWithOptions(configs.DotnetTestLogger, string(testrunner.DotnetTestLoggerTRX), string(testrunner.DotnetTestLoggerNUnit)); err != nil {
+		return fmt.Errorf("DotnetTestLogger - %s", err)
 	}
-	if err := input.ValidateIfNotEmpty(configs.XamarinConfiguration); err != nil {
-		return fmt.Errorf("XamarinConfiguration - %s", err)
+	if _, err := shellquote.Split(configs.Nunit2Options); err != nil {
+		return fmt.Errorf("Nunit2Options - failed to split params, error: %s", err)
 	}
-	if err := input.ValidateIfNotEmpty(configs.XamarinPlatform); err != nil {
-		return fmt.Errorf("XamarinPlatform - %s", err)
+	if _, err := shellquote.Split(configs.NunitLiteOptions); err != nil {
+		return fmt.Errorf("NunitLiteOptions - failed to split params, error: %s", err)
+	}
+	if _, err := shellquote.Split(configs.DotnetTestOptions); err != nil {
+		return fmt.Errorf("DotnetTestOptions - failed to split params, error: %s", err)
+	}
+
+	if err := configs.projectFilter().Validate(); err != nil {
+		return fmt.Errorf("Test project selection - %s", err)
+	}
+
+	if err := input.ValidateWithOptions(configs.ProjectOrder, string(testrunner.ProjectOrderSolution), string(testrunner.ProjectOrderDependency)); err != nil {
+		return fmt.Errorf("ProjectOrder - %s", err)
+	}
+	if err := (testrunner.ProjectFilter{Include: filter.SplitList(configs.ProjectPriority)}).Validate(); err != nil {
+		return fmt.Errorf("ProjectPriority - %s", err)
+	}
+
+	if concurrency, err := strconv.Atoi(configs.Concurrency); err != nil || concurrency < 1 {
+		return fmt.Errorf("Concurrency - should be a positive number, got: %s", configs.Concurrency)
+	}
+
+	shardCount, err := strconv.Atoi(configs.ShardCount)
+	if err != nil || shardCount < 1 {
+		return fmt.Errorf("ShardCount - should be a positive number, got: %s", configs.ShardCount)
+	}
+	if shardIndex, err := strconv.Atoi(configs.ShardIndex); err != nil || shardIndex < 0 || shardIndex >= shardCount {
+		return fmt.Errorf("ShardIndex - should be a number between 0 and %d, got: %s", shardCount-1, configs.ShardIndex)
+	}
+	if err := input.ValidateWithOptions(configs.ShardBy, shardByFixture, shardByTest); err != nil {
+		return fmt.Errorf("ShardBy - %s", err)
+	}
+
+	testFilter := configs.testFilter()
+	if err := testFilter.Validate(); err != nil {
+		return fmt.Errorf("Test selection - %s", err)
+	}
+	if !testFilter.IsEmpty() {
+		options, err := shellquote.Split(configs.CustomOptions)
+		if err != nil {
+			return fmt.Errorf("CustomOptions - failed to split params, error: %s", err)
+		}
+		for _, option := range options {
+			if option == "--where" || strings.HasPrefix(option, "--where=") {
+				return fmt.Errorf("CustomOptions - the --where option can not be used together with the test selection inputs, use the test_filter input instead")
+			}
+		}
+	}
+
+	if err := input.ValidateWithOptions(configs.FailOnMissingResults, "true", "false"); err != nil {
+		return fmt.Errorf("FailOnMissingResults - %s", err)
+	}
+	if err := input.ValidateWithOptions(configs.FailOnNoTestsExecuted, "true", "false"); err != nil {
+		return fmt.Errorf("FailOnNoTestsExecuted - %s", err)
+	}
+	if err := input.ValidateWithOptions(configs.FailOnTestFailures, "true", "false"); err != nil {
+		return fmt.Errorf("FailOnTestFailures - %s", err)
+	}
+	if configs.QuarantineFile != "" {
+		if err := input.ValidateIfPathExists(configs.QuarantineFile); err != nil {
+			return fmt.Errorf("QuarantineFile - %s", err)
+		}
 	}
 
 	if err := input.ValidateWithOptions(configs.BuildBeforeRun, "true", "false"); err != nil {
 		return fmt.Errorf("BuildBeforeRun - %s", err)
 	}
+	if err := input.ValidateWithOptions(configs.BuildScope, string(testrunner.BuildScopeSolution), string(testrunner.BuildScopeTestProjects)); err != nil {
+		return fmt.Errorf("BuildScope - %s", err)
+	}
 	if err := input.ValidateWithOptions(configs.BuildTool, "msbuild", "xbuild"); err != nil {
 		return fmt.Errorf("BuildTool - %s", err)
 	}
+	if err := input.ValidateWithOptions(configs.DryRun, "true", "false"); err != nil {
+		return fmt.Errorf("DryRun - %s", err)
+	}
+
+	return nil
+}
+
+// buildBeforeRun reports whether the solution is built before running the tests, the test assemblies are not built.
+func (configs ConfigsModel) buildBeforeRun() bool {
+	return configs.BuildBeforeRun == "true" && configs.TestAssemblies == ""
+}
+
+func (configs ConfigsModel) testFilter() filter.Model {
+	return filter.Model{
+		Where:             configs.TestFilter,
+		IncludeCategories: filter.SplitList(configs.IncludeCategories, ","),
+		ExcludeCategories: filter.SplitList(configs.ExcludeCategories, ","),
+		TestNamePatterns:  filter.SplitList(configs.TestNamePatterns),
+	}
+}
+
+func (configs ConfigsModel) projectFilter() testrunner.ProjectFilter {
+	return testrunner.ProjectFilter{
+		Include: filter.SplitList(configs.IncludeProjects),
+		Exclude: filter.SplitList(configs.ExcludeProjects),
+	}
+}
+
+func (configs ConfigsModel) resultPolicy(quarantined quarantine.List) outcome.Policy {
+	return outcome.Policy{
+		FailOnMissingResults:  configs.FailOnMissingResults == "true",
+		FailOnNoTestsExecuted: configs.FailOnNoTestsExecuted == "true",
+		FailOnTestFailures:    configs.FailOnTestFailures == "true",
+		Quarantine:            quarantined,
+	}
+}
+
+// findNunitConsoles finds the consoles used by the runner (nunit3-console and the NUnit 2 nunit-console) and sets their paths on it.
+func findNunitConsoles(configs ConfigsModel, runner *testrunner.Model) error {
+	if runner.UsesNunitConsole(configs.XamarinConfiguration, configs.XamarinPlatform) {
+		console, err := runner.FindNunitConsole(configs.NunitConsolePath)
+		if err != nil {
+			return err
+		}
+		logNunitConsole(console)
+
+		runner.SetNunitConsolePath(console.Pth)
+	}
+
+	if runner.UsesNunit2Console(configs.XamarinConfiguration, configs.XamarinPlatform) {
+		console, err := runner.FindNunit2Console(configs.Nunit2ConsolePath)
+		if err != nil {
+			return err
+		}
+		logNunitConsole(console)
+
+		runner.SetNunit2ConsolePath(console.Pth)
+	}
+
+	return nil
+}
+
+func logNunitConsole(console testrunner.NunitConsole) {
+	version := console.Version
+	if version == "" {
+		version = "unknown version"
+	}
+	log.Printf("Using nunit console (%s, from %s): %s", version, console.Source, console.Pth)
+}
+
+// resolveTools resolves the tools used by the runner (mono, the build tool and dotnet) and sets their paths on it.
+func resolveTools(configs ConfigsModel, runner *testrunner.Model) error {
+	fmt.Println()
+	log.Infof("Tools:")
+
+	monoPth, buildToolPth := "", ""
+
+	configuration, platform := configs.XamarinConfiguration, configs.XamarinPlatform
+	if runner.UsesNunitConsole(configuration, platform) || runner.UsesNunit2Console(configuration, platform) || runner.UsesNunitLite(configuration, platform) {
+		mono, err := toolchain.Resolve(toolchain.Mono, configs.MonoPath)
+		if err != nil {
+			return err
+		}
+		log.Printf("- %s", mono)
+
+		monoPth = mono.Pth
+	}
+
+	if configs.buildBeforeRun() {
+		buildToolName, explicitBuildToolPth := toolchain.Msbuild, configs.MsbuildPath
+		if configs.BuildTool == "xbuild" {
+			buildToolName, explicitBuildToolPth = toolchain.Xbuild, configs.XbuildPath
+		}
+
+		buildTool, err := toolchain.Resolve(buildToolName, explicitBuildToolPth)
+		if err != nil {
+			return err
+		}
+		log.Printf("- %s", buildTool)
+
+		buildToolPth = buildTool.Pth
+	}
+
+	runner.SetToolPaths(monoPth, buildToolPth)
+
+	if runner.UsesDotnetTest(configuration, platform) {
+		dotnet, err := toolchain.Resolve(toolchain.Dotnet, configs.DotnetPath)
+		if err != nil {
+			return err
+		}
+		log.Printf("- %s", dotnet)
+
+		runner.SetDotnetPath(dotnet.Pth)
+	}
 
 	return nil
 }
@@ -95,6 +433,65 @@ func testResultLogContent(pth string) (string, error) {
 	return content, nil
 }
 
+func mergeTestResults(projectResults []projectTestResult, mergedPth string) (testresult.TestRun, error) {
+	runs := []testresult.TestRun{}
+	for _, projectResult := range projectResults {
+		runs = append(runs, projectResult.Run)
+	}
+
+	merged := testresult.Merge(runs...)
+	if err := merged.WriteFile(mergedPth); err != nil {
+		return testresult.TestRun{}, err
+	}
+
+	return merged, nil
+}
+
+func exportFailed(reason outcome.FailureReason) {
+	if err := steptools.ExportEnvironmentWithEnvman("BITRISE_XAMARIN_TEST_RESULT", "failed"); err != nil {
+		log.Warnf("Failed to export environment: %s, error: %s", "BITRISE_XAMARIN_TEST_RESULT", err)
+	}
+
+	if err := steptools.ExportEnvironmentWithEnvman("BITRISE_XAMARIN_TEST_FAILURE_REASON", string(reason)); err != nil {
+		log.Warnf("Failed to export environment: %s, error: %s", "BITRISE_XAMARIN_TEST_FAILURE_REASON", err)
+	}
+}
+
+func exportTestRunCounts(run testresult.TestRun) {
+	outputs := []struct {
+		key   string
+		value string
+	}{
+		{"BITRISE_XAMARIN_TEST_TOTAL_COUNT", strconv.Itoa(run.Total)},
+		{"BITRISE_XAMARIN_TEST_PASSED_COUNT", strconv.Itoa(run.Passed)},
+		{"BITRISE_XAMARIN_TEST_FAILED_COUNT", strconv.Itoa(run.Failed)},
+		{"BITRISE_XAMARIN_TEST_SKIPPED_COUNT", strconv.Itoa(run.Skipped)},
+		{"BITRISE_XAMARIN_TEST_INCONCLUSIVE_COUNT", strconv.Itoa(run.Inconclusive)},
+		{"BITRISE_XAMARIN_TEST_WARNING_COUNT", strconv.Itoa(run.Warnings)},
+		{"BITRISE_XAMARIN_TEST_DURATION", strconv.FormatFloat(run.Duration, 'f', 3, 64)},
+	}
+
+	for _, output := range outputs {
+		if err := steptools.ExportEnvironmentWithEnvman(output.key, output.value); err != nil {
+			log.Warnf("Failed to export environment: %s, error: %s", output.key, err)
+		}
+	}
+}
+
+func exportFlakyTests(run testresult.TestRun) {
+	flakyTestNames := run.FlakyTestNames()
+
+	if err := steptools.ExportEnvironmentWithEnvman("BITRISE_XAMARIN_TEST_FLAKY_COUNT", strconv.Itoa(len(flakyTestNames))); err != nil {
+		log.Warnf("Failed to export environment: %s, error: %s", "BITRISE_XAMARIN_TEST_FLAKY_COUNT", err)
+	}
+
+	if len(flakyTestNames) > 0 {
+		if err := steptools.ExportEnvironmentWithEnvman("BITRISE_XAMARIN_TEST_FLAKY_TESTS", strings.Join(flakyTestNames, "\n")); err != nil {
+			log.Warnf("Failed to export environment: %s, error: %s", "BITRISE_XAMARIN_TEST_FLAKY_TESTS", err)
+		}
+	}
+}
+
 func main() {
 	configs := createConfigsModelFromEnvs()
 
@@ -104,68 +501,194 @@ func main() {
 	if err := configs.validate(); err != nil {
 		log.Errorf("Issue with input: %s", err)
 
-		if err := steptools.ExportEnvironmentWithEnvman("BITRISE_XAMARIN_TEST_RESULT", "failed"); err != nil {
-			log.Warnf("Failed to export environment: %s, error: %s", "BITRISE_XAMARIN_TEST_RESULT", err)
-		}
+		exportFailed(outcome.FailureReasonSetupError)
 
 		os.Exit(1)
 	}
 
 	// Custom Options
 	resultLogPth := filepath.Join(configs.DeployDir, "TestResult.xml")
-	customOptions := []string{"--result", resultLogPth}
+	customOptions := []string{}
 	if configs.CustomOptions != "" {
 		options, err := shellquote.Split(configs.CustomOptions)
 		if err != nil {
 			log.Errorf("Failed to split params (%s), error: %s", configs.CustomOptions, err)
 
-			if err := steptools.ExportEnvironmentWithEnvman("BITRISE_XAMARIN_TEST_RESULT", "failed"); err != nil {
-				log.Warnf("Failed to export environment: %s, error: %s", "BITRISE_XAMARIN_TEST_RESULT", err)
-			}
+			exportFailed(outcome.FailureReasonSetupError)
 
 			os.Exit(1)
 		}
 
-		customOptions = append(customOptions, options...)
+		customOptions = options
+	}
+
+	nunit2Options, err := shellquote.Split(configs.Nunit2Options)
+	if err != nil {
+		log.Errorf("Failed to split params (%s), error: %s", configs.Nunit2Options, err)
+
+		exportFailed(outcome.FailureReasonSetupError)
+
+		os.Exit(1)
+	}
+
+	nunitLiteOptions, err := shellquote.Split(configs.NunitLiteOptions)
+	if err != nil {
+		log.Errorf("Failed to split params (%s), error: %s", configs.NunitLiteOptions, err)
+
+		exportFailed(outcome.FailureReasonSetupError)
+
+		os.Exit(1)
+	}
+
+	dotnetTestOptions, err := shellquote.Split(configs.DotnetTestOptions)
+	if err != nil {
+		log.Errorf("Failed to split params (%s), error: %s", configs.DotnetTestOptions, err)
+
+		exportFailed(outcome.FailureReasonSetupError)
+
+		os.Exit(1)
+	}
+
+	testFilter := configs.testFilter()
+	if expression := testFilter.Expression(); expression != "" {
+		log.Printf("Test filter: %s", expression)
 	}
 	// ---
 
+	quarantined := quarantine.List{}
+	if configs.QuarantineFile != "" {
+		list, err := quarantine.ParseFile(configs.QuarantineFile)
+		if err != nil {
+			log.Errorf("Failed to read quarantine file, error: %s", err)
+
+			exportFailed(outcome.FailureReasonSetupError)
+
+			os.Exit(1)
+		}
+
+		quarantined = list
+	}
+
 	//
 	// build
 	fmt.Println()
-	log.Infof("Running all nunit test projects in solution: %s", configs.XamarinSolution)
 
-	buildTool := buildtools.Msbuild
-	if configs.BuildTool == "xbuild" {
-		buildTool = buildtools.Xbuild
-	}
+	var runner testrunner.Model
 
-	builder, err := builder.New(configs.XamarinSolution, []constants.SDK{}, buildTool)
-	if err != nil {
-		log.Errorf("Failed to create xamarin builder, error: %s", err)
+	if configs.TestAssemblies != "" {
+		log.Infof("Running the test assemblies: %s", strings.Join(filter.SplitList(configs.TestAssemblies), ", "))
+		if configs.BuildBeforeRun == "true" {
+			log.Warnf("The test assemblies are not built, build_before_test is ignored")
+		}
+
+		runner, err = testrunner.NewWithTestAssemblies(filter.SplitList(configs.TestAssemblies))
+	} else {
+		log.Infof("Running all nunit test projects in solution: %s", configs.XamarinSolution)
 
-		if err := steptools.ExportEnvironmentWithEnvman("BITRISE_XAMARIN_TEST_RESULT", "failed"); err != nil {
-			log.Warnf("Failed to export environment: %s, error: %s", "BITRISE_XAMARIN_TEST_RESULT", err)
+		buildTool := buildtools.Msbuild
+		if configs.BuildTool == "xbuild" {
+			buildTool = buildtools.Xbuild
 		}
 
+		runner, err = testrunner.New(configs.XamarinSolution, buildTool)
+	}
+	if err != nil {
+		log.Errorf("Failed to create test runner, error: %s", err)
+
+		exportFailed(outcome.FailureReasonSetupError)
+
+		os.Exit(1)
+	}
+	if !runner.IsTestAssemblies() {
+		runner.SetTestRunner(testrunner.TestRunnerMode(configs.TestRunner), testrunner.DotnetTestLogger(configs.DotnetTestLogger))
+		runner.SetBuildScope(testrunner.BuildScope(configs.BuildScope))
+	}
+	runner.SetTestFilter(testFilter)
+	runner.SetTestInput(testrunner.TestInput(configs.TestInput), configs.SingleInvocation == "true")
+	runner.SetCustomOptions(testrunner.CustomOptions{
+		NunitConsole:  customOptions,
+		Nunit2Console: nunit2Options,
+		NunitLite:     nunitLiteOptions,
+		DotnetTest:    dotnetTestOptions,
+	})
+
+	// the consoles and the tools are resolved for the selected test projects only
+	runner.SetProjectFilter(configs.projectFilter())
+	runner.SetProjectOrder(testrunner.ProjectOrder(configs.ProjectOrder), filter.SplitList(configs.ProjectPriority))
+
+	// the test filter is checked before anything is built
+	if err := runner.ValidateTestFilter(configs.XamarinConfiguration, configs.XamarinPlatform); err != nil {
+		log.Errorf("Issue with input: %s", err)
+
+		exportFailed(outcome.FailureReasonSetupError)
+
 		os.Exit(1)
 	}
 
+	if err := findNunitConsoles(configs, &runner); err != nil && configs.DryRun != "true" {
+		log.Errorf("Failed to find nunit console, error: %s", err)
+
+		exportFailed(outcome.FailureReasonSetupError)
+
+		os.Exit(1)
+	}
+
+	if err := resolveTools(configs, &runner); err != nil {
+		if configs.DryRun != "true" {
+			log.Errorf("Failed to resolve tools, error: %s", err)
+
+			exportFailed(outcome.FailureReasonSetupError)
+
+			os.Exit(1)
+		}
+		log.Warnf("Failed to resolve tools, error: %s", err)
+	}
+
+	if concurrency, _ := strconv.Atoi(configs.Concurrency); concurrency > 1 {
+		log.Printf("Running at most %d test projects at the same time, the output of a project is printed when it finished", concurrency)
+		runner.SetConcurrency(concurrency)
+	}
+
+	testProjectNames := []string{}
+	projectResultLogPth := func(projectName string) string {
+		return filepath.Join(configs.DeployDir, fmt.Sprintf("%s_TestResult.xml", projectName))
+	}
+
+	// the test lists of the current shard, by project name
+	shardTestLists := map[string]testList{}
+
 	prepareCallback := func(solutionName string, projectName string, sdk constants.SDK, projectType constants.TestFramework, command *tools.Editable) {
 		if projectType == constants.TestFrameworkNunitTest {
-			(*command).SetCustomOptions(customOptions...)
+			testProjectNames = append(testProjectNames, projectName)
+
+			testCommand, ok := (*command).(testrunner.TestCommand)
+			if !ok {
+				return
+			}
+
+			testCommand.SetResultLogPth(projectResultLogPth(projectName))
+			if list, ok := shardTestLists[projectName]; ok {
+				testCommand.SetTestList(list.Pth, list.TestNames)
+			}
 		}
 	}
 
+	// the stage of the run, to categorize its error: it is reset to setup at the start of each phase,
+	// and set to build or test when a build or a test command starts
+	stage := outcome.StageSetup
+
 	callback := func(solutionName string, projectName string, sdk constants.SDK, projectType constants.TestFramework, commandStr string, alreadyPerformed bool) {
 		fmt.Println()
 		if projectName == "" {
 			log.Infof("Building solution: %s", solutionName)
+			stage = outcome.StageBuild
 		} else {
 			if projectType == constants.TestFrameworkNunitTest {
 				log.Infof("Running test project: %s", projectName)
+				stage = outcome.StageTest
 			} else {
 				log.Infof("Building project: %s", projectName)
+				stage = outcome.StageBuild
 			}
 		}
 
@@ -178,25 +701,162 @@ func main() {
 		fmt.Println()
 	}
 
+	shardIndex, _ := strconv.Atoi(configs.ShardIndex)
+	shardCount, _ := strconv.Atoi(configs.ShardCount)
+
+	if configs.DryRun == "true" {
+		plan, planErr := runner.Plan(configs.XamarinConfiguration, configs.XamarinPlatform, configs.buildBeforeRun(), prepareCallback)
+		if shardCount > 1 {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("The tests of shard %d/%d are listed at run time, the test commands run with a generated --testlist", shardIndex+1, shardCount))
+		}
+
+		printPlan(plan)
+
+		planPth := filepath.Join(configs.DeployDir, "TestPlan.json")
+		if writeErr := plan.WriteFile(planPth); writeErr != nil {
+			log.Warnf("Failed to write plan, error: %s", writeErr)
+		} else if expErr := steptools.ExportEnvironmentWithEnvman("BITRISE_XAMARIN_TEST_PLAN_PATH", planPth); expErr != nil {
+			log.Warnf("Failed to export environment: %s, error: %s", "BITRISE_XAMARIN_TEST_PLAN_PATH", expErr)
+		}
+
+		if planErr != nil {
+			fmt.Println()
+			log.Errorf("Failed to create plan, error: %s", planErr)
+
+			exportFailed(outcome.FailureReasonSetupError)
+
+			os.Exit(1)
+		}
+
+		fmt.Println()
+		log.Donef("Dry run, nothing was executed")
+
+		return
+	}
+
 	var warnings []string
 	err = nil
+	emptyShard := false
 
-	if configs.BuildBeforeRun == "true" {
-		warnings, err = builder.BuildAndRunAllNunitTestProjects(configs.XamarinConfiguration, configs.XamarinPlatform, callback, prepareCallback)
-	} else {
-		warnings, err = builder.RunAllNunitTestProjects(configs.XamarinConfiguration, configs.XamarinPlatform, callback, prepareCallback)
+	if configs.buildBeforeRun() {
+		warnings, err = runner.Build(configs.XamarinConfiguration, configs.XamarinPlatform, callback)
+	}
+
+	if err == nil && shardCount > 1 {
+		stage = outcome.StageSetup
+
+		var shardProjectNames []string
+		shardProjectNames, shardTestLists, err = prepareShard(runner, configs, shardIndex, shardCount, callback)
+		if err == nil && len(shardProjectNames) == 0 {
+			// more shards than tests: the empty shard succeeds with an empty test result
+			fmt.Println()
+			log.Warnf("No test in shard %d/%d", shardIndex+1, shardCount)
+			emptyShard = true
+		} else if err == nil {
+			stage = outcome.StageSetup
+
+			var warns []string
+			warns, err = runner.RunNunitTestProjects(configs.XamarinConfiguration, configs.XamarinPlatform, shardProjectNames, callback, prepareCallback)
+			warnings = append(warnings, warns...)
+		}
+	} else if err == nil {
+		stage = outcome.StageSetup
+
+		var warns []string
+		warns, err = runner.RunAllNunitTestProjects(configs.XamarinConfiguration, configs.XamarinPlatform, callback, prepareCallback)
+		warnings = append(warnings, warns...)
 	}
 
 	for _, warning := range warnings {
 		log.Warnf(warning)
 	}
 
-	if err != nil {
-		log.Errorf("Test run failed, error: %s", err)
+	projectResults := []projectTestResult{}
+	for _, projectName := range testProjectNames {
+		pth := projectResultLogPth(projectName)
+		if exist, err := pathutil.IsPathExists(pth); err != nil {
+			log.Warnf("Failed to check if path (%s) exist, error: %s", pth, err)
+		} else if exist {
+			run, err := testresult.ParseFile(pth)
+			if err != nil {
+				log.Warnf("Failed to read test result of %s, error: %s", projectName, err)
+				continue
+			}
 
-		if err := steptools.ExportEnvironmentWithEnvman("BITRISE_XAMARIN_TEST_RESULT", "failed"); err != nil {
-			log.Warnf("Failed to export environment: %s, error: %s", "BITRISE_XAMARIN_TEST_RESULT", err)
+			projectResults = append(projectResults, projectTestResult{
+				ProjectName:  projectName,
+				ResultLogPth: pth,
+				Run:          run,
+			})
 		}
+	}
+
+	if retryCount, _ := strconv.Atoi(configs.RetryCount); retryCount > 0 && outcome.RunErrorReason(err, stage) == outcome.FailureReasonTestFailures {
+		flakyTestNames, retryErr := retryFailedTests(runner, configs, projectResults, retryCount, callback)
+		if retryErr != nil {
+			log.Warnf("Failed to retry failed tests, error: %s", retryErr)
+		}
+
+		allProjectResultsAvailable := len(projectResults) == len(testProjectNames)
+		if len(flakyTestNames) > 0 && allProjectResultsAvailable && !hasFailedTests(projectResults) {
+			// every failed test passed on retry
+			err = nil
+		}
+	}
+
+	var testRun *testresult.TestRun
+	if len(projectResults) > 0 || emptyShard {
+		if run, mergeErr := mergeTestResults(projectResults, resultLogPth); mergeErr != nil {
+			log.Warnf("Failed to merge test results, error: %s", mergeErr)
+		} else {
+			testRun = &run
+		}
+	}
+
+	if testRun != nil {
+		junitResultPth := filepath.Join(configs.DeployDir, "TestResult.junit.xml")
+		if junitErr := junit.Convert(*testRun).WriteFile(junitResultPth); junitErr != nil {
+			log.Warnf("Failed to write junit test result, error: %s", junitErr)
+		} else if expErr := steptools.ExportEnvironmentWithEnvman("BITRISE_XAMARIN_TEST_JUNIT_RESULT_PATH", junitResultPth); expErr != nil {
+			log.Warnf("Failed to export environment: %s, error: %s", "BITRISE_XAMARIN_TEST_JUNIT_RESULT_PATH", expErr)
+		}
+
+		timingsPth := filepath.Join(configs.DeployDir, "TestTimings.json")
+		if timingsErr := writeTimings(*testRun, projectResults, configs.ShardTimingsPath, timingsPth); timingsErr != nil {
+			log.Warnf("Failed to write test timings, error: %s", timingsErr)
+		} else if expErr := steptools.ExportEnvironmentWithEnvman("BITRISE_XAMARIN_TEST_TIMINGS_PATH", timingsPth); expErr != nil {
+			log.Warnf("Failed to export environment: %s, error: %s", "BITRISE_XAMARIN_TEST_TIMINGS_PATH", expErr)
+		}
+
+		exportTestRunCounts(*testRun)
+		exportFlakyTests(*testRun)
+		report.PrintFlakyTests(*testRun)
+		if expression, parseErr := testFilter.Parse(); parseErr != nil {
+			log.Warnf("Failed to parse test filter, error: %s", parseErr)
+		} else {
+			report.PrintFilterMismatches(*testRun, expression)
+		}
+		report.PrintQuarantine(*testRun, quarantined)
+		report.PrintFailures(*testRun, quarantined)
+	}
+
+	policy := configs.resultPolicy(quarantined)
+	if emptyShard {
+		policy.FailOnNoTestsExecuted = false
+	}
+	decision := policy.Evaluate(err, stage, testRun)
+
+	fmt.Println()
+	for _, warning := range decision.Warnings {
+		log.Warnf(warning)
+	}
+
+	if decision.Failed {
+		for _, message := range decision.Messages {
+			log.Errorf(message)
+		}
+
+		exportFailed(decision.Reason)
 
 		os.Exit(1)
 	}
@@ -210,6 +870,10 @@ func main() {
 		log.Warnf("Failed to export environment: %s, error: %s", "BITRISE_XAMARIN_TEST_RESULT", expErr)
 	}
 
+	if expErr := steptools.ExportEnvironmentWithEnvman("BITRISE_XAMARIN_TEST_FAILURE_REASON", string(outcome.FailureReasonNone)); expErr != nil {
+		log.Warnf("Failed to export environment: %s, error: %s", "BITRISE_XAMARIN_TEST_FAILURE_REASON", expErr)
+	}
+
 	if resultLog != "" {
 		if expErr := steptools.ExportEnvironmentWithEnvman("BITRISE_XAMARIN_TEST_FULL_RESULTS_TEXT", resultLog); expErr != nil {
 			log.Warnf("Failed to export environment: %s, error: %s", "BITRISE_XAMARIN_TEST_FULL_RESULTS_TEXT", expErr)
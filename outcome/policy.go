@@ -0,0 +1,119 @@
+package outcome
+
+import (
+	"fmt"
+
+	"github.com/bitrise-steplib/steps-nunit-runner/quarantine"
+	"github.com/bitrise-steplib/steps-nunit-runner/testresult"
+)
+
+// Policy describes which conditions fail the step.
+type Policy struct {
+	FailOnMissingResults  bool
+	FailOnNoTestsExecuted bool
+	FailOnTestFailures    bool
+	// failures of the quarantined tests do not fail the step
+	Quarantine quarantine.List
+}
+
+// Decision ...
+type Decision struct {
+	Failed   bool
+	Reason   FailureReason
+	Messages []string
+	Warnings []string
+}
+
+func (decision *Decision) fail(reason FailureReason, format string, v ...interface{}) {
+	if !decision.Failed {
+		decision.Reason = reason
+	}
+	decision.Failed = true
+	decision.Messages = append(decision.Messages, fmt.Sprintf(format, v...))
+}
+
+func (decision *Decision) warn(format string, v ...interface{}) {
+	decision.Warnings = append(decision.Warnings, fmt.Sprintf(format, v...))
+}
+
+// Evaluate decides the outcome of the step, based on the error of the build or test command,
+// the stage in which the error occurred and the parsed test results (run is nil if no result was found).
+func (policy Policy) Evaluate(runErr error, stage Stage, run *testresult.TestRun) Decision {
+	decision := Decision{Reason: FailureReasonNone}
+
+	runErrReason := RunErrorReason(runErr, stage)
+	anyTestFailed := run != nil && (run.Failed > 0 || run.Result == testresult.ResultFailed)
+
+	failed, quarantinedFailed := 0, 0
+	if run != nil {
+		failed, quarantinedFailed = policy.countFailures(*run)
+	}
+	testsFailed := anyTestFailed && (failed > 0 || quarantinedFailed == 0)
+
+	if runErr != nil {
+		switch {
+		case runErrReason != FailureReasonTestFailures:
+			decision.fail(runErrReason, "Test run failed (%s), error: %s", runErrReason, runErr)
+		case !anyTestFailed:
+			// the exit code reports failed tests, but the results of the failing project are not available
+			decision.fail(runErrReason, "Test run failed, error: %s", runErr)
+		case !testsFailed || !policy.FailOnTestFailures:
+			decision.warn("Test run failed, error: %s", runErr)
+		}
+	}
+
+	if run == nil {
+		if policy.FailOnMissingResults {
+			decision.fail(FailureReasonMissingResults, "No test result found")
+		} else {
+			decision.warn("No test result found")
+		}
+
+		return decision
+	}
+
+	if quarantinedFailed > 0 {
+		decision.warn("%d quarantined test(s) failed", quarantinedFailed)
+	}
+
+	if testsFailed {
+		message := fmt.Sprintf("%d test(s) failed", failed)
+		if failed == 0 {
+			message = fmt.Sprintf("Test run result: %s", run.Result)
+		}
+
+		if policy.FailOnTestFailures {
+			decision.fail(FailureReasonTestFailures, "%s", message)
+		} else {
+			decision.warn("%s", message)
+		}
+	}
+
+	executed := run.Passed + run.Failed + run.Warnings + run.Inconclusive
+	if executed == 0 {
+		if policy.FailOnNoTestsExecuted {
+			decision.fail(FailureReasonNoTestsExecuted, "No test was executed")
+		} else {
+			decision.warn("No test was executed")
+		}
+	}
+
+	return decision
+}
+
+// countFailures returns the number of failed test cases, which are not quarantined, and the number of the quarantined ones.
+func (policy Policy) countFailures(run testresult.TestRun) (int, int) {
+	failed, quarantined := 0, 0
+	for _, testCase := range run.TestCases() {
+		if !testCase.IsFailed() {
+			continue
+		}
+
+		if _, ok := policy.Quarantine.Match(testCase.FullName); ok {
+			quarantined++
+		} else {
+			failed++
+		}
+	}
+	return failed, quarantined
+}
@@ -0,0 +1,112 @@
+package outcome
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bitrise-steplib/steps-nunit-runner/quarantine"
+	"github.com/bitrise-steplib/steps-nunit-runner/testresult"
+)
+
+// testRun returns a recounted run of the test cases, by their full names and results.
+func testRun(results map[string]string) *testresult.TestRun {
+	fixture := testresult.TestSuite{Type: "TestFixture", Name: "LoginTests", FullName: "MyApp.Tests.LoginTests"}
+	for _, name := range []string{"MyApp.Tests.LoginTests.Valid", "MyApp.Tests.LoginTests.Invalid", "MyApp.Tests.LoginTests.Slow"} {
+		if result, ok := results[name]; ok {
+			fixture.TestCases = append(fixture.TestCases, testresult.TestCase{FullName: name, Result: result})
+		}
+	}
+
+	run := testresult.TestRun{TestSuites: []testresult.TestSuite{fixture}}
+	run.Recount()
+	return &run
+}
+
+func TestEvaluate(t *testing.T) {
+	strict := Policy{FailOnMissingResults: true, FailOnNoTestsExecuted: true, FailOnTestFailures: true}
+	lenient := Policy{}
+
+	quarantined := strict
+	quarantined.Quarantine = quarantine.List{{Test: "MyApp.Tests.LoginTests.Sl*", Owner: "jane"}}
+
+	passed := testRun(map[string]string{
+		"MyApp.Tests.LoginTests.Valid": testresult.ResultPassed,
+	})
+	failed := testRun(map[string]string{
+		"MyApp.Tests.LoginTests.Valid":   testresult.ResultPassed,
+		"MyApp.Tests.LoginTests.Invalid": testresult.ResultFailed,
+	})
+	slowFailed := testRun(map[string]string{
+		"MyApp.Tests.LoginTests.Valid": testresult.ResultPassed,
+		"MyApp.Tests.LoginTests.Slow":  testresult.ResultFailed,
+	})
+	bothFailed := testRun(map[string]string{
+		"MyApp.Tests.LoginTests.Invalid": testresult.ResultFailed,
+		"MyApp.Tests.LoginTests.Slow":    testresult.ResultFailed,
+	})
+	skipped := testRun(map[string]string{
+		"MyApp.Tests.LoginTests.Valid": testresult.ResultSkipped,
+	})
+
+	for _, tc := range []struct {
+		name     string
+		policy   Policy
+		err      error
+		stage    Stage
+		run      *testresult.TestRun
+		expected Decision
+	}{
+		{
+			name: "passed", policy: strict, stage: StageTest, run: passed,
+			expected: Decision{Reason: FailureReasonNone},
+		},
+		{
+			name: "test failures", policy: strict, err: exitError(t, 1), stage: StageTest, run: failed,
+			expected: Decision{Failed: true, Reason: FailureReasonTestFailures, Messages: []string{"1 test(s) failed"}},
+		},
+		{
+			name: "test failures not failing the step", policy: lenient, err: exitError(t, 1), stage: StageTest, run: failed,
+			expected: Decision{Reason: FailureReasonNone, Warnings: []string{"Test run failed, error: exit status 1", "1 test(s) failed"}},
+		},
+		{
+			name: "quarantined test failure", policy: quarantined, err: exitError(t, 1), stage: StageTest, run: slowFailed,
+			expected: Decision{Reason: FailureReasonNone, Warnings: []string{"Test run failed, error: exit status 1", "1 quarantined test(s) failed"}},
+		},
+		{
+			name: "quarantined and not quarantined test failures", policy: quarantined, err: exitError(t, 2), stage: StageTest, run: bothFailed,
+			expected: Decision{Failed: true, Reason: FailureReasonTestFailures, Messages: []string{"1 test(s) failed"}, Warnings: []string{"1 quarantined test(s) failed"}},
+		},
+		{
+			name: "runner error with test failures", policy: lenient, err: exitError(t, 255), stage: StageTest, run: failed,
+			expected: Decision{Failed: true, Reason: FailureReasonInvalidArgument, Messages: []string{"Test run failed (invalid_argument), error: exit status 255"}, Warnings: []string{"1 test(s) failed"}},
+		},
+		{
+			name: "exit code without failed tests", policy: strict, err: exitError(t, 1), stage: StageTest, run: passed,
+			expected: Decision{Failed: true, Reason: FailureReasonTestFailures, Messages: []string{"Test run failed, error: exit status 1"}},
+		},
+		{
+			name: "build failure", policy: strict, err: BuildError{Err: exitError(t, 1)}, stage: StageTest,
+			expected: Decision{Failed: true, Reason: FailureReasonBuildFailed, Messages: []string{"Test run failed (build_failed), error: exit status 1", "No test result found"}},
+		},
+		{
+			name: "missing results", policy: strict, stage: StageTest,
+			expected: Decision{Failed: true, Reason: FailureReasonMissingResults, Messages: []string{"No test result found"}},
+		},
+		{
+			name: "missing results not failing the step", policy: lenient, stage: StageTest,
+			expected: Decision{Reason: FailureReasonNone, Warnings: []string{"No test result found"}},
+		},
+		{
+			name: "no tests executed", policy: strict, stage: StageTest, run: skipped,
+			expected: Decision{Failed: true, Reason: FailureReasonNoTestsExecuted, Messages: []string{"No test was executed"}},
+		},
+		{
+			name: "no tests executed not failing the step", policy: lenient, stage: StageTest, run: skipped,
+			expected: Decision{Reason: FailureReasonNone, Warnings: []string{"No test was executed"}},
+		},
+	} {
+		if decision := tc.policy.Evaluate(tc.err, tc.stage, tc.run); !reflect.DeepEqual(decision, tc.expected) {
+			t.Errorf("%s: expected: %+v, got: %+v", tc.name, tc.expected, decision)
+		}
+	}
+}
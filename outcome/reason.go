@@ -0,0 +1,183 @@
+package outcome
+
+import "github.com/bitrise-io/go-utils/errorutil"
+
+// FailureReason categorizes why the step failed.
+type FailureReason string
+
+const (
+	// FailureReasonNone ...
+	FailureReasonNone FailureReason = "none"
+	// FailureReasonSetupError ...
+	FailureReasonSetupError FailureReason = "setup_error"
+	// FailureReasonBuildFailed ...
+	FailureReasonBuildFailed FailureReason = "build_failed"
+	// FailureReasonTestFailures ...
+	FailureReasonTestFailures FailureReason = "test_failures"
+	// FailureReasonNoTestsExecuted ...
+	FailureReasonNoTestsExecuted FailureReason = "no_tests_executed"
+	// FailureReasonMissingResults ...
+	FailureReasonMissingResults FailureReason = "missing_results"
+	// FailureReasonInvalidArgument ...
+	FailureReasonInvalidArgument FailureReason = "invalid_argument"
+	// FailureReasonInvalidAssembly ...
+	FailureReasonInvalidAssembly FailureReason = "invalid_assembly"
+	// FailureReasonInvalidTestFixture ...
+	FailureReasonInvalidTestFixture FailureReason = "invalid_test_fixture"
+	// FailureReasonUnloadError ...
+	FailureReasonUnloadError FailureReason = "unload_error"
+	// FailureReasonUnexpectedError ...
+	FailureReasonUnexpectedError FailureReason = "unexpected_error"
+	// FailureReasonUnknown ...
+	FailureReasonUnknown FailureReason = "unknown"
+)
+
+// Stage of the step, in which the run error occurred.
+type Stage string
+
+const (
+	// StageSetup ...
+	StageSetup Stage = "setup"
+	// StageBuild ...
+	StageBuild Stage = "build"
+	// StageTest ...
+	StageTest Stage = "test"
+)
+
+// BuildError is the error of a test command, which failed to build the tests,
+// for example dotnet test builds the test project before running its tests.
+type BuildError struct {
+	Err error
+}
+
+// Error ...
+func (e BuildError) Error() string {
+	return e.Err.Error()
+}
+
+// TestRunner is the test runner of a test command, which defines the meaning of its exit codes.
+type TestRunner string
+
+const (
+	// TestRunnerNunitConsole is nunit3-console, NUnitLite uses the same exit codes.
+	TestRunnerNunitConsole TestRunner = "nunit3-console"
+	// TestRunnerNunit2Console is the NUnit 2 nunit-console.
+	TestRunnerNunit2Console TestRunner = "nunit2-console"
+)
+
+// TestCommandError is the error of a test command, with its test runner
+// and the number of the failed tests in the test result it wrote.
+type TestCommandError struct {
+	Err    error
+	Runner TestRunner
+	// ResultFailed is the number of the failed tests in the test result, -1 if no test result was written
+	ResultFailed int
+}
+
+// Error ...
+func (e TestCommandError) Error() string {
+	return e.Err.Error()
+}
+
+// nunit3-console return codes, as seen by the parent process (the negative codes are truncated to a byte).
+const (
+	nunitExitCodeInvalidArg         = 255 // -1
+	nunitExitCodeInvalidAssembly    = 254 // -2
+	nunitExitCodeInvalidTestFixture = 252 // -4
+	nunitExitCodeUnloadError        = 251 // -5
+	nunitExitCodeUnexpectedError    = 156 // -100
+)
+
+// NunitConsoleExitCodeReason maps a nunit3-console exit code to a failure reason.
+// Positive exit codes (other than the error codes) are the number of the failed tests.
+func NunitConsoleExitCodeReason(exitCode int) FailureReason {
+	switch exitCode {
+	case 0:
+		return FailureReasonNone
+	case nunitExitCodeInvalidArg, -1:
+		return FailureReasonInvalidArgument
+	case nunitExitCodeInvalidAssembly, -2:
+		return FailureReasonInvalidAssembly
+	case nunitExitCodeInvalidTestFixture, -4:
+		return FailureReasonInvalidTestFixture
+	case nunitExitCodeUnloadError, -5:
+		return FailureReasonUnloadError
+	case nunitExitCodeUnexpectedError, -100:
+		return FailureReasonUnexpectedError
+	}
+
+	if exitCode > 0 {
+		return FailureReasonTestFailures
+	}
+	return FailureReasonUnknown
+}
+
+// NUnit 2 nunit-console return codes, as seen by the parent process (the negative codes are truncated to a byte).
+const (
+	nunit2ExitCodeInvalidArg      = 255 // -1
+	nunit2ExitCodeFileNotFound    = 254 // -2
+	nunit2ExitCodeFixtureNotFound = 253 // -3
+	nunit2ExitCodeUnexpectedError = 156 // -100
+)
+
+// Nunit2ConsoleExitCodeReason maps an NUnit 2 nunit-console exit code to a failure reason.
+// Positive exit codes (other than the error codes) are the number of the failed tests.
+func Nunit2ConsoleExitCodeReason(exitCode int) FailureReason {
+	switch exitCode {
+	case 0:
+		return FailureReasonNone
+	case nunit2ExitCodeInvalidArg, -1:
+		return FailureReasonInvalidArgument
+	case nunit2ExitCodeFileNotFound, -2:
+		return FailureReasonInvalidAssembly
+	case nunit2ExitCodeFixtureNotFound, -3:
+		return FailureReasonInvalidTestFixture
+	case nunit2ExitCodeUnexpectedError, -100:
+		return FailureReasonUnexpectedError
+	}
+
+	if exitCode > 0 {
+		return FailureReasonTestFailures
+	}
+	return FailureReasonUnknown
+}
+
+// RunErrorReason categorizes the error returned by the build or the test command.
+func RunErrorReason(runErr error, stage Stage) FailureReason {
+	if runErr == nil {
+		return FailureReasonNone
+	}
+
+	if _, ok := runErr.(BuildError); ok {
+		return FailureReasonBuildFailed
+	}
+
+	switch stage {
+	case StageSetup:
+		return FailureReasonSetupError
+	case StageBuild:
+		return FailureReasonBuildFailed
+	}
+
+	commandErr, isTestCommandErr := runErr.(TestCommandError)
+	if isTestCommandErr {
+		runErr = commandErr.Err
+	}
+
+	exitCode, err := errorutil.CmdExitCodeFromError(runErr)
+	if err != nil || exitCode == 0 {
+		// the command did not exit with an exit code (for example it failed to start)
+		return FailureReasonUnknown
+	}
+
+	// the exit code is the number of the failed tests truncated to a byte, which can collide with the error codes:
+	// the exit code is an error code only if it does not match the failed tests of the written test result
+	if isTestCommandErr && commandErr.ResultFailed > 0 && (commandErr.ResultFailed-exitCode)%256 == 0 {
+		return FailureReasonTestFailures
+	}
+
+	if isTestCommandErr && commandErr.Runner == TestRunnerNunit2Console {
+		return Nunit2ConsoleExitCodeReason(exitCode)
+	}
+	return NunitConsoleExitCodeReason(exitCode)
+}
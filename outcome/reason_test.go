@@ -0,0 +1,133 @@
+package outcome
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+)
+
+// exitError returns the error of a command exiting with the exit code.
+func exitError(t *testing.T, exitCode int) error {
+	err := exec.Command("sh", "-c", fmt.Sprintf("exit %d", exitCode)).Run()
+	if err == nil {
+		t.Fatalf("expected the command to exit with: %d", exitCode)
+	}
+	return err
+}
+
+func TestNunitConsoleExitCodeReason(t *testing.T) {
+	for _, tc := range []struct {
+		exitCode int
+		expected FailureReason
+	}{
+		{0, FailureReasonNone},
+		{1, FailureReasonTestFailures},
+		{12, FailureReasonTestFailures},
+		{253, FailureReasonTestFailures},
+		{255, FailureReasonInvalidArgument},
+		{-1, FailureReasonInvalidArgument},
+		{254, FailureReasonInvalidAssembly},
+		{-2, FailureReasonInvalidAssembly},
+		{252, FailureReasonInvalidTestFixture},
+		{-4, FailureReasonInvalidTestFixture},
+		{251, FailureReasonUnloadError},
+		{-5, FailureReasonUnloadError},
+		{156, FailureReasonUnexpectedError},
+		{-100, FailureReasonUnexpectedError},
+		{-3, FailureReasonUnknown},
+	} {
+		if reason := NunitConsoleExitCodeReason(tc.exitCode); reason != tc.expected {
+			t.Errorf("%d: expected: %s, got: %s", tc.exitCode, tc.expected, reason)
+		}
+	}
+}
+
+func TestNunit2ConsoleExitCodeReason(t *testing.T) {
+	for _, tc := range []struct {
+		exitCode int
+		expected FailureReason
+	}{
+		{0, FailureReasonNone},
+		{1, FailureReasonTestFailures},
+		{252, FailureReasonTestFailures},
+		{251, FailureReasonTestFailures},
+		{255, FailureReasonInvalidArgument},
+		{254, FailureReasonInvalidAssembly},
+		{253, FailureReasonInvalidTestFixture},
+		{-3, FailureReasonInvalidTestFixture},
+		{156, FailureReasonUnexpectedError},
+		{-4, FailureReasonUnknown},
+	} {
+		if reason := Nunit2ConsoleExitCodeReason(tc.exitCode); reason != tc.expected {
+			t.Errorf("%d: expected: %s, got: %s", tc.exitCode, tc.expected, reason)
+		}
+	}
+}
+
+func TestRunErrorReason(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		err      error
+		stage    Stage
+		expected FailureReason
+	}{
+		{"no error", nil, StageTest, FailureReasonNone},
+		{"setup", exitError(t, 1), StageSetup, FailureReasonSetupError},
+		{"build", exitError(t, 1), StageBuild, FailureReasonBuildFailed},
+		{"dotnet test build", BuildError{Err: errors.New("Failed to build Sdk.Tests")}, StageTest, FailureReasonBuildFailed},
+		{"not an exit error", errors.New("failed"), StageTest, FailureReasonUnknown},
+		{"test failures", exitError(t, 3), StageTest, FailureReasonTestFailures},
+		{"invalid argument", exitError(t, 255), StageTest, FailureReasonInvalidArgument},
+		{"unexpected error", exitError(t, 156), StageTest, FailureReasonUnexpectedError},
+		{
+			"255 failed tests",
+			TestCommandError{Err: exitError(t, 255), Runner: TestRunnerNunitConsole, ResultFailed: 255},
+			StageTest, FailureReasonTestFailures,
+		},
+		{
+			"511 failed tests, truncated to 255",
+			TestCommandError{Err: exitError(t, 255), Runner: TestRunnerNunitConsole, ResultFailed: 511},
+			StageTest, FailureReasonTestFailures,
+		},
+		{
+			"156 failed tests",
+			TestCommandError{Err: exitError(t, 156), Runner: TestRunnerNunitConsole, ResultFailed: 156},
+			StageTest, FailureReasonTestFailures,
+		},
+		{
+			"invalid argument without test result",
+			TestCommandError{Err: exitError(t, 255), Runner: TestRunnerNunitConsole, ResultFailed: -1},
+			StageTest, FailureReasonInvalidArgument,
+		},
+		{
+			"invalid assembly with mismatching failed count",
+			TestCommandError{Err: exitError(t, 254), Runner: TestRunnerNunitConsole, ResultFailed: 2},
+			StageTest, FailureReasonInvalidAssembly,
+		},
+		{
+			"unload error without failed tests",
+			TestCommandError{Err: exitError(t, 251), Runner: TestRunnerNunitConsole, ResultFailed: 0},
+			StageTest, FailureReasonUnloadError,
+		},
+		{
+			"NUnit 2 fixture not found",
+			TestCommandError{Err: exitError(t, 253), Runner: TestRunnerNunit2Console, ResultFailed: -1},
+			StageTest, FailureReasonInvalidTestFixture,
+		},
+		{
+			"NUnit 2 253 failed tests",
+			TestCommandError{Err: exitError(t, 253), Runner: TestRunnerNunit2Console, ResultFailed: 253},
+			StageTest, FailureReasonTestFailures,
+		},
+		{
+			"NUnit 2 exit code of nunit3-console invalid test fixture",
+			TestCommandError{Err: exitError(t, 252), Runner: TestRunnerNunit2Console, ResultFailed: -1},
+			StageTest, FailureReasonTestFailures,
+		},
+	} {
+		if reason := RunErrorReason(tc.err, tc.stage); reason != tc.expected {
+			t.Errorf("%s: expected: %s, got: %s", tc.name, tc.expected, reason)
+		}
+	}
+}
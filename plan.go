@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/bitrise-steplib/steps-nunit-runner/testrunner"
+)
+
+func printPlan(plan testrunner.Plan) {
+	fmt.Println()
+	log.Infof("Plan:")
+
+	if plan.Solution != "" {
+		log.Printf("Solution: %s", plan.Solution)
+		log.Printf("Solution config: %s", plan.SolutionConfig)
+	} else {
+		log.Printf("Solution: - (test assemblies)")
+	}
+
+	if len(plan.BuildCommands) > 0 {
+		fmt.Println()
+		log.Infof("Build:")
+		for _, buildCommand := range plan.BuildCommands {
+			log.Donef("$ %s", buildCommand)
+		}
+	}
+
+	fmt.Println()
+	log.Infof("Test projects (%d):", len(plan.Projects))
+	for _, proj := range plan.Projects {
+		log.Printf("- %s (%s)", proj.Name, proj.Pth)
+		if proj.OutputDir != "" {
+			log.Printf("  project config: %s|%s, output dir: %s", proj.Configuration, proj.Platform, proj.OutputDir)
+		} else if proj.Configuration != "" || proj.Platform != "" {
+			log.Printf("  project config: %s|%s", proj.Configuration, proj.Platform)
+		}
+		log.Donef("  $ %s", proj.Command)
+	}
+
+	if len(plan.SkippedProjects) > 0 {
+		fmt.Println()
+		log.Infof("Skipped test projects (%d):", len(plan.SkippedProjects))
+		for _, skipped := range plan.SkippedProjects {
+			log.Printf("- %s: %s", skipped.Name, skipped.Reason)
+		}
+	}
+
+	if len(plan.Warnings) > 0 {
+		fmt.Println()
+		for _, warning := range plan.Warnings {
+			log.Warnf(warning)
+		}
+	}
+}
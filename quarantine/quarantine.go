@@ -0,0 +1,61 @@
+package quarantine
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-steplib/steps-nunit-runner/filter"
+)
+
+// Entry is a quarantined test (or test pattern), with the owner of the test and the reason of the quarantine.
+type Entry struct {
+	Test   string `json:"test"`
+	Owner  string `json:"owner"`
+	Reason string `json:"reason"`
+}
+
+// List ...
+type List []Entry
+
+// ParseFile reads a quarantine file, like:
+//
+//	[
+//	  { "test": "MyApp.Tests.ApiTests.*", "owner": "jane", "reason": "staging API is unstable" }
+//	]
+//
+// The test is a fully qualified test name or a pattern, where * matches any sequence of characters and ? matches a single character,
+// the same way as the test name patterns of the test filter.
+func ParseFile(pth string) (List, error) {
+	content, err := fileutil.ReadBytesFromFile(pth)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read file (%s), error: %s", pth, err)
+	}
+
+	var list List
+	if err := json.Unmarshal(content, &list); err != nil {
+		return nil, fmt.Errorf("Failed to parse quarantine file (%s), error: %s", pth, err)
+	}
+
+	for i, entry := range list {
+		if entry.Test == "" {
+			return nil, fmt.Errorf("Quarantine entry #%d: test not specified", i+1)
+		}
+	}
+
+	return list, nil
+}
+
+// Match returns the first entry, which matches the fully qualified test name.
+func (list List) Match(testName string) (Entry, bool) {
+	for _, entry := range list {
+		if entry.Test == testName {
+			return entry, true
+		}
+		if match, err := regexp.MatchString(filter.GlobToRegexp(entry.Test), testName); err == nil && match {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
@@ -0,0 +1,67 @@
+package quarantine
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseFile(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "quarantine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	pth := filepath.Join(tmpDir, "quarantine.json")
+	content := `[
+  { "test": "MyApp.Tests.ArrayTests.Sum(System.Int32[])", "owner": "jane", "reason": "overflows" },
+  { "test": "MyApp.Tests.UrlTests.*" }
+]`
+	if err := ioutil.WriteFile(pth, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := ParseFile(pth)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(list) != 2 || list[0].Owner != "jane" || list[0].Reason != "overflows" {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+
+	if err := ioutil.WriteFile(pth, []byte(`[{ "owner": "jane" }]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseFile(pth); err == nil {
+		t.Fatal("expected an error for the entry without test")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	list := List{
+		{Test: "MyApp.Tests.ArrayTests.Sum(System.Int32[])", Owner: "jane"},
+		{Test: "MyApp.Tests.UrlTests.*", Owner: "joe"},
+		{Test: "MyApp.Tests.Calc?.Add"},
+	}
+
+	for _, tc := range []struct {
+		testName string
+		owner    string
+		match    bool
+	}{
+		{"MyApp.Tests.ArrayTests.Sum(System.Int32[])", "jane", true},
+		{"MyApp.Tests.ArrayTests.Sum(System.Int32)", "", false},
+		{`MyApp.Tests.UrlTests.Parse("http://a/b")`, "joe", true},
+		{"MyApp.Tests.UrlTests.Nested.Parse", "joe", true},
+		{"MyApp.Tests.UrlTest", "", false},
+		{"MyApp.Tests.Calc2.Add", "", true},
+		{"MyApp.Tests.Calc.Add", "", false},
+	} {
+		entry, match := list.Match(tc.testName)
+		if match != tc.match || entry.Owner != tc.owner {
+			t.Errorf("%s: expected match: %v (owner: %s), got: %v (owner: %s)", tc.testName, tc.match, tc.owner, match, entry.Owner)
+		}
+	}
+}
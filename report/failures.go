@@ -0,0 +1,140 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/bitrise-steplib/steps-nunit-runner/quarantine"
+	"github.com/bitrise-steplib/steps-nunit-runner/testresult"
+)
+
+const maxStackTraceFrames = 6
+
+// stack frames of the test framework and of the runtime, which are not relevant for the failure
+var ignoredStackFramePrefixes = []string{
+	"at NUnit.Framework.",
+	"at NUnit.",
+	"at System.Reflection.",
+	"at System.RuntimeMethodHandle.",
+	"at (wrapper ",
+}
+
+// FailureGroup holds the failed test cases of a test fixture.
+type FailureGroup struct {
+	ClassName string
+	TestCases []testresult.TestCase
+}
+
+// GroupFailures collects the failed test cases of the run, which are not quarantined, grouped by their fixture, in document order.
+func GroupFailures(run testresult.TestRun, quarantined quarantine.List) []FailureGroup {
+	groups := []FailureGroup{}
+	groupIdxByClassName := map[string]int{}
+
+	for _, testCase := range run.TestCases() {
+		if !testCase.IsFailed() {
+			continue
+		}
+		if _, ok := quarantined.Match(testCase.FullName); ok {
+			continue
+		}
+
+		idx, ok := groupIdxByClassName[testCase.ClassName]
+		if !ok {
+			idx = len(groups)
+			groupIdxByClassName[testCase.ClassName] = idx
+			groups = append(groups, FailureGroup{ClassName: testCase.ClassName})
+		}
+
+		groups[idx].TestCases = append(groups[idx].TestCases, testCase)
+	}
+
+	return groups
+}
+
+// PrintFailures prints a digest of the failed test cases of the run:
+// their fully qualified name, the failure message and the relevant part of the stack trace.
+// The quarantined test cases are left out, see PrintQuarantine.
+func PrintFailures(run testresult.TestRun, quarantined quarantine.List) {
+	groups := GroupFailures(run, quarantined)
+	if len(groups) == 0 {
+		return
+	}
+
+	failed := 0
+	for _, group := range groups {
+		failed += len(group.TestCases)
+	}
+
+	fmt.Println()
+	log.Errorf("Failed tests (%d):", failed)
+
+	for _, group := range groups {
+		fmt.Println()
+		log.Infof("%s", group.ClassName)
+
+		for _, testCase := range group.TestCases {
+			printFailure(testCase)
+		}
+	}
+}
+
+func printFailure(testCase testresult.TestCase) {
+	title := testCase.FullName
+	if testCase.Label != "" {
+		title = fmt.Sprintf("%s (%s)", title, testCase.Label)
+	}
+	log.Errorf("  x %s", title)
+
+	if testCase.Failure == nil {
+		return
+	}
+
+	for _, line := range nonEmptyLines(testCase.Failure.Message) {
+		log.Printf("      %s", line)
+	}
+
+	frames, omitted := TrimStackTrace(testCase.Failure.StackTrace, maxStackTraceFrames)
+	for _, frame := range frames {
+		log.Printf("        %s", frame)
+	}
+	if omitted > 0 {
+		log.Printf("        ... %d more frame(s)", omitted)
+	}
+}
+
+// TrimStackTrace drops the test framework and runtime frames of the stack trace
+// and keeps at most maxFrames of the remaining ones. It returns the kept frames and the number of the dropped ones.
+func TrimStackTrace(stackTrace string, maxFrames int) ([]string, int) {
+	frames := []string{}
+	omitted := 0
+
+	for _, line := range nonEmptyLines(stackTrace) {
+		ignored := false
+		for _, prefix := range ignoredStackFramePrefixes {
+			if strings.HasPrefix(line, prefix) {
+				ignored = true
+				break
+			}
+		}
+
+		if ignored || len(frames) >= maxFrames {
+			omitted++
+			continue
+		}
+
+		frames = append(frames, line)
+	}
+
+	return frames, omitted
+}
+
+func nonEmptyLines(text string) []string {
+	lines := []string{}
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,39 @@
+package report
+
+import (
+	"fmt"
+
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/bitrise-steplib/steps-nunit-runner/filter"
+	"github.com/bitrise-steplib/steps-nunit-runner/testresult"
+)
+
+// PrintFilterMismatches prints the test cases of the run, which are not selected by the test filter,
+// for example the tests of a runner ignoring the filter, or of a filter passed in the custom options as well.
+func PrintFilterMismatches(run testresult.TestRun, expression filter.Expression) {
+	if expression == nil {
+		return
+	}
+
+	selected := map[string]bool{}
+	for _, testCase := range filter.SelectTestCases(run, expression) {
+		selected[testCase.FullName] = true
+	}
+
+	notSelected := []string{}
+	for _, testCase := range run.TestCases() {
+		if !selected[testCase.FullName] {
+			notSelected = append(notSelected, testCase.FullName)
+		}
+	}
+	if len(notSelected) == 0 {
+		return
+	}
+
+	fmt.Println()
+	log.Warnf("Tests not selected by the test filter (%d), but found in the test results:", len(notSelected))
+
+	for _, name := range notSelected {
+		log.Printf("  - %s", name)
+	}
+}
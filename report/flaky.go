@@ -0,0 +1,23 @@
+package report
+
+import (
+	"fmt"
+
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/bitrise-steplib/steps-nunit-runner/testresult"
+)
+
+// PrintFlakyTests prints the tests, which failed at first, but passed on a retry.
+func PrintFlakyTests(run testresult.TestRun) {
+	flakyTestNames := run.FlakyTestNames()
+	if len(flakyTestNames) == 0 {
+		return
+	}
+
+	fmt.Println()
+	log.Warnf("Flaky tests (%d), failed at first but passed on retry:", len(flakyTestNames))
+
+	for _, name := range flakyTestNames {
+		log.Printf("  ~ %s", name)
+	}
+}
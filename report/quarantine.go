@@ -0,0 +1,60 @@
+package report
+
+import (
+	"fmt"
+
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/bitrise-steplib/steps-nunit-runner/quarantine"
+	"github.com/bitrise-steplib/steps-nunit-runner/testresult"
+)
+
+// PrintQuarantine prints the quarantined tests of the run: the failed ones with their owner and the reason of the quarantine,
+// and the ones which passed, so they can be removed from the quarantine list.
+func PrintQuarantine(run testresult.TestRun, quarantined quarantine.List) {
+	if len(quarantined) == 0 {
+		return
+	}
+
+	failed := []testresult.TestCase{}
+	passed := []testresult.TestCase{}
+	for _, testCase := range run.TestCases() {
+		if _, ok := quarantined.Match(testCase.FullName); !ok {
+			continue
+		}
+
+		switch testCase.Result {
+		case testresult.ResultFailed:
+			failed = append(failed, testCase)
+		case testresult.ResultPassed:
+			passed = append(passed, testCase)
+		}
+	}
+
+	if len(failed) > 0 {
+		fmt.Println()
+		log.Warnf("Quarantined tests failed (%d), these failures do not fail the step:", len(failed))
+
+		for _, testCase := range failed {
+			entry, _ := quarantined.Match(testCase.FullName)
+			log.Printf("  x %s", testCase.FullName)
+			log.Printf("      owner: %s, reason: %s", valueOrNone(entry.Owner), valueOrNone(entry.Reason))
+		}
+	}
+
+	if len(passed) > 0 {
+		fmt.Println()
+		log.Warnf("Quarantined tests passed (%d), consider removing them from the quarantine list:", len(passed))
+
+		for _, testCase := range passed {
+			entry, _ := quarantined.Match(testCase.FullName)
+			log.Printf("  ✓ %s (quarantined by: %s)", testCase.FullName, valueOrNone(entry.Test))
+		}
+	}
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
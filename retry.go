@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-steplib/steps-nunit-runner/filter"
+	"github.com/bitrise-steplib/steps-nunit-runner/testresult"
+	"github.com/bitrise-steplib/steps-nunit-runner/testrunner"
+	"github.com/bitrise-tools/go-xamarin/builder"
+	"github.com/bitrise-tools/go-xamarin/constants"
+	"github.com/bitrise-tools/go-xamarin/tools"
+)
+
+// projectTestResult holds the test result of a test project.
+type projectTestResult struct {
+	ProjectName  string
+	ResultLogPth string
+	Run          testresult.TestRun
+}
+
+// retryFailedTests reruns the failed tests of the test projects, at most retryCount times,
+// and applies the rerun results to the project results (and to their result files).
+// Only the failed tests selected by the test filter are retried, the rerun would not select the rest of them.
+// It returns the full names of the flaky tests: the tests failed at first, but passed on a retry.
+func retryFailedTests(runner testrunner.Model, configs ConfigsModel, projectResults []projectTestResult, retryCount int, callback builder.BuildCommandCallback) ([]string, error) {
+	flakyTestNames := []string{}
+
+	expression, err := configs.testFilter().Parse()
+	if err != nil {
+		return flakyTestNames, fmt.Errorf("Failed to parse test filter, error: %s", err)
+	}
+
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("nunit-retry")
+	if err != nil {
+		return flakyTestNames, fmt.Errorf("Failed to create tmp dir, error: %s", err)
+	}
+
+	for attempt := 1; attempt <= retryCount; attempt++ {
+		retried := false
+
+		for i, projectResult := range projectResults {
+			failedTestNames, notSelectedTestNames := retryTestNames(projectResult.Run, expression)
+			if attempt == 1 && len(notSelectedTestNames) > 0 {
+				log.Warnf("%d failed test(s) of %s are not selected by the test filter, not retrying them: %s", len(notSelectedTestNames), projectResult.ProjectName, strings.Join(notSelectedTestNames, ", "))
+			}
+			if len(failedTestNames) == 0 {
+				continue
+			}
+			retried = true
+
+			fmt.Println()
+			log.Infof("Retrying %d failed test(s) of %s (attempt %d/%d)", len(failedTestNames), projectResult.ProjectName, attempt, retryCount)
+
+			testListPth := filepath.Join(tmpDir, fmt.Sprintf("%s_retry_%d.txt", projectResult.ProjectName, attempt))
+			if err := fileutil.WriteStringToFile(testListPth, strings.Join(failedTestNames, "\n")+"\n"); err != nil {
+				return flakyTestNames, fmt.Errorf("Failed to write test list, error: %s", err)
+			}
+
+			rerunResultLogPth := filepath.Join(tmpDir, fmt.Sprintf("%s_TestResult_retry_%d.xml", projectResult.ProjectName, attempt))
+
+			prepareCallback := func(solutionName string, projectName string, sdk constants.SDK, projectType constants.TestFramework, command *tools.Editable) {
+				if testCommand, ok := (*command).(testrunner.TestCommand); ok {
+					testCommand.SetResultLogPth(rerunResultLogPth)
+					testCommand.SetTestList(testListPth, failedTestNames)
+				}
+			}
+
+			// failing tests make the rerun fail, the outcome is decided by the rerun results
+			warnings, runErr := runner.RunNunitTestProject(configs.XamarinConfiguration, configs.XamarinPlatform, projectResult.ProjectName, callback, prepareCallback)
+			for _, warning := range warnings {
+				log.Warnf(warning)
+			}
+
+			rerun, err := testresult.ParseFile(rerunResultLogPth)
+			if err != nil {
+				log.Warnf("Failed to read retry result of %s, error: %s, test run error: %v", projectResult.ProjectName, err, runErr)
+				continue
+			}
+
+			flakyTestNames = append(flakyTestNames, projectResults[i].Run.ApplyRerun(rerun)...)
+
+			if err := projectResults[i].Run.WriteFile(projectResult.ResultLogPth); err != nil {
+				log.Warnf("Failed to update test result of %s, error: %s", projectResult.ProjectName, err)
+			}
+		}
+
+		if !retried {
+			break
+		}
+	}
+
+	return flakyTestNames, nil
+}
+
+// retryTestNames returns the full names of the failed tests to retry: the failed tests selected by the test filter,
+// and the full names of the failed tests not selected by it.
+func retryTestNames(run testresult.TestRun, expression filter.Expression) ([]string, []string) {
+	failedTestNames := run.FailedTestNames()
+	if expression == nil {
+		return failedTestNames, nil
+	}
+
+	selected := map[string]bool{}
+	for _, testCase := range filter.SelectTestCases(run, expression) {
+		selected[testCase.FullName] = true
+	}
+
+	retried, notSelected := []string{}, []string{}
+	for _, name := range failedTestNames {
+		if selected[name] {
+			retried = append(retried, name)
+		} else {
+			notSelected = append(notSelected, name)
+		}
+	}
+	return retried, notSelected
+}
+
+func hasFailedTests(projectResults []projectTestResult) bool {
+	for _, projectResult := range projectResults {
+		if projectResult.Run.Failed > 0 {
+			return true
+		}
+	}
+	return false
+}
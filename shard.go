@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/log"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-steplib/steps-nunit-runner/shard"
+	"github.com/bitrise-steplib/steps-nunit-runner/testresult"
+	"github.com/bitrise-steplib/steps-nunit-runner/testrunner"
+	"github.com/bitrise-tools/go-xamarin/builder"
+	"github.com/bitrise-tools/go-xamarin/constants"
+	"github.com/bitrise-tools/go-xamarin/tools"
+)
+
+// Shard units
+const (
+	shardByFixture = "fixture"
+	shardByTest    = "test"
+)
+
+// testList is a list of tests to run, written to a file.
+type testList struct {
+	Pth       string
+	TestNames []string
+}
+
+// wholeProjectItemPrefix marks the shard items of the test projects, which run as a whole on a single shard.
+const wholeProjectItemPrefix = "project:"
+
+// prepareShard lists the tests of the test projects (with the --explore option of nunit3-console),
+// splits them into shards, balanced by the timings, and writes the test lists of the current shard.
+// The test projects, which can not list their tests (dotnet test, NUnit 2), are not split: each of them is a single shard item.
+// It returns the names of the test projects having tests in the current shard, and their test lists
+// (the test projects running as a whole do not have a test list).
+func prepareShard(runner testrunner.Model, configs ConfigsModel, shardIndex, shardCount int, callback builder.BuildCommandCallback) ([]string, map[string]testList, error) {
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("nunit-shard")
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to create tmp dir, error: %s", err)
+	}
+
+	explorePth := func(projectName string) string {
+		return filepath.Join(tmpDir, fmt.Sprintf("%s_explore.xml", projectName))
+	}
+
+	testProjectNames, warnings, err := runner.TestProjectNames(configs.XamarinConfiguration, configs.XamarinPlatform)
+	for _, warning := range warnings {
+		log.Warnf(warning)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(testProjectNames) == 0 {
+		return nil, nil, fmt.Errorf("No project to build found")
+	}
+
+	exploredProjectNames, wholeProjectNames := []string{}, []string{}
+	for _, projectName := range testProjectNames {
+		if runner.ListsTests(projectName) {
+			exploredProjectNames = append(exploredProjectNames, projectName)
+		} else {
+			wholeProjectNames = append(wholeProjectNames, projectName)
+		}
+	}
+
+	if len(exploredProjectNames) > 0 {
+		prepareCallback := func(solutionName string, projectName string, sdk constants.SDK, projectType constants.TestFramework, command *tools.Editable) {
+			if testCommand, ok := (*command).(testrunner.TestCommand); ok {
+				testCommand.SetExplorePth(explorePth(projectName))
+			}
+		}
+
+		fmt.Println()
+		log.Infof("Listing the tests of the test projects")
+
+		warns, err := runner.RunNunitTestProjects(configs.XamarinConfiguration, configs.XamarinPlatform, exploredProjectNames, callback, prepareCallback)
+		reported := map[string]bool{}
+		for _, warning := range warnings {
+			reported[warning] = true
+		}
+		for _, warning := range warns {
+			// the skipped test projects are already reported
+			if !reported[warning] {
+				log.Warnf(warning)
+			}
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// the items to split, and the projects containing them
+	items := []string{}
+	projectNamesByItem := map[string][]string{}
+	for _, projectName := range wholeProjectNames {
+		log.Printf("- %s: the tests can not be listed, the test project runs as a whole on a single shard", projectName)
+
+		item := wholeProjectItemPrefix + projectName
+		items = append(items, item)
+		projectNamesByItem[item] = []string{projectName}
+	}
+	for _, projectName := range exploredProjectNames {
+		run, err := testresult.ParseFile(explorePth(projectName))
+		if err != nil {
+			return nil, nil, fmt.Errorf("Failed to read the tests of %s, error: %s", projectName, err)
+		}
+
+		for _, testCase := range run.TestCases() {
+			item := testCase.FullName
+			if configs.ShardBy == shardByFixture && testCase.ClassName != "" {
+				item = testCase.ClassName
+			}
+
+			projectNames, ok := projectNamesByItem[item]
+			if !ok {
+				items = append(items, item)
+			}
+			if len(projectNames) == 0 || projectNames[len(projectNames)-1] != projectName {
+				projectNamesByItem[item] = append(projectNames, projectName)
+			}
+		}
+	}
+
+	timings := shard.NewTimings()
+	if configs.ShardTimingsPath != "" {
+		if exist, err := pathutil.IsPathExists(configs.ShardTimingsPath); err != nil {
+			log.Warnf("Failed to check if path (%s) exist, error: %s", configs.ShardTimingsPath, err)
+		} else if !exist {
+			log.Warnf("Timings file not exist at: %s, splitting the tests evenly", configs.ShardTimingsPath)
+		} else if t, err := shard.ReadTimings(configs.ShardTimingsPath); err != nil {
+			log.Warnf("Failed to read timings, error: %s, splitting the tests evenly", err)
+		} else {
+			timings = t
+		}
+	}
+
+	itemDurations := timings.Tests
+	if configs.ShardBy == shardByFixture {
+		itemDurations = timings.Fixtures
+	}
+
+	durations := map[string]float64{}
+	for item, duration := range itemDurations {
+		durations[item] = duration
+	}
+
+	// a test project running as a whole takes as long as its tests,
+	// without its duration it is estimated with the average duration of as many items as it had in the previous run
+	weights := map[string]int{}
+	for _, projectName := range wholeProjectNames {
+		item := wholeProjectItemPrefix + projectName
+		timing, ok := timings.Projects[projectName]
+		if !ok {
+			continue
+		}
+
+		if timing.Duration > 0 {
+			durations[item] = timing.Duration
+		}
+		weights[item] = timing.Tests
+		if configs.ShardBy == shardByFixture {
+			weights[item] = timing.Fixtures
+		}
+	}
+
+	shards, estimatedDurations := shard.Partition(items, shardCount, durations, weights)
+	shardItems := shards[shardIndex]
+
+	fmt.Println()
+	log.Infof("Shard %d/%d: %d of %d %s(s), estimated duration: %.1fs", shardIndex+1, shardCount, len(shardItems), len(items), configs.ShardBy, estimatedDurations[shardIndex])
+
+	testListByProjectName := map[string][]string{}
+	inShard := map[string]bool{}
+	for _, item := range shardItems {
+		for _, projectName := range projectNamesByItem[item] {
+			inShard[projectName] = true
+			if !strings.HasPrefix(item, wholeProjectItemPrefix) {
+				testListByProjectName[projectName] = append(testListByProjectName[projectName], item)
+			}
+		}
+	}
+
+	projectNames := []string{}
+	testLists := map[string]testList{}
+	for _, projectName := range testProjectNames {
+		if !inShard[projectName] {
+			log.Printf("- %s: no test in this shard, skipping...", projectName)
+			continue
+		}
+
+		testNames, ok := testListByProjectName[projectName]
+		if !ok {
+			log.Printf("- %s: every test", projectName)
+			projectNames = append(projectNames, projectName)
+			continue
+		}
+		log.Printf("- %s: %d %s(s)", projectName, len(testNames), configs.ShardBy)
+
+		testListPth := filepath.Join(tmpDir, fmt.Sprintf("%s_shard_%d.txt", projectName, shardIndex))
+		if err := fileutil.WriteStringToFile(testListPth, strings.Join(testNames, "\n")+"\n"); err != nil {
+			return nil, nil, fmt.Errorf("Failed to write test list, error: %s", err)
+		}
+
+		projectNames = append(projectNames, projectName)
+		testLists[projectName] = testList{Pth: testListPth, TestNames: testNames}
+	}
+
+	return projectNames, testLists, nil
+}
+
+// writeTimings updates the timings of the previous runs (if any) with the timings of the run and of its test projects.
+func writeTimings(run testresult.TestRun, projectResults []projectTestResult, previousTimingsPth, pth string) error {
+	timings := shard.NewTimings()
+	if previousTimingsPth != "" {
+		if exist, err := pathutil.IsPathExists(previousTimingsPth); err != nil {
+			return fmt.Errorf("Failed to check if path (%s) exist, error: %s", previousTimingsPth, err)
+		} else if exist {
+			previous, err := shard.ReadTimings(previousTimingsPth)
+			if err != nil {
+				return err
+			}
+			timings = previous
+		}
+	}
+
+	newer := shard.TimingsFromRun(run)
+	for _, projectResult := range projectResults {
+		newer.Projects[projectResult.ProjectName] = shard.ProjectTimingFromRun(projectResult.Run)
+	}
+	timings.Update(newer)
+
+	return timings.WriteFile(pth)
+}
@@ -0,0 +1,77 @@
+package shard
+
+import "sort"
+
+// Partition splits the items (test or fixture names) into count shards, balanced by their durations.
+// Items without a known duration are estimated with the average of the known durations,
+// multiplied by their weight: the number of tests (or fixtures) an item stands for (1 if not in weights).
+// The split is deterministic: the same items and durations always result in the same shards,
+// each shard keeps the original order of its items. It returns the shards and their estimated durations.
+func Partition(items []string, count int, durations map[string]float64, weights map[string]int) ([][]string, []float64) {
+	if count < 1 {
+		return nil, nil
+	}
+	shards := make([][]string, count)
+
+	estimated := estimateDurations(items, durations, weights)
+
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return estimated[order[i]] > estimated[order[j]]
+	})
+
+	// longest processing time first: the next longest item goes to the shortest shard
+	assigned := make([]int, len(items))
+	totals := make([]float64, count)
+	for _, idx := range order {
+		shortest := 0
+		for shard := 1; shard < count; shard++ {
+			if totals[shard] < totals[shortest] {
+				shortest = shard
+			}
+		}
+
+		assigned[idx] = shortest
+		totals[shortest] += estimated[idx]
+	}
+
+	for idx, item := range items {
+		shards[assigned[idx]] = append(shards[assigned[idx]], item)
+	}
+	return shards, totals
+}
+
+func estimateDurations(items []string, durations map[string]float64, weights map[string]int) []float64 {
+	weight := func(item string) int {
+		if w, ok := weights[item]; ok && w > 0 {
+			return w
+		}
+		return 1
+	}
+
+	known, sum := 0, 0.0
+	for _, item := range items {
+		if duration, ok := durations[item]; ok {
+			known += weight(item)
+			sum += duration
+		}
+	}
+
+	average := 1.0
+	if known > 0 && sum > 0 {
+		average = sum / float64(known)
+	}
+
+	estimated := make([]float64, len(items))
+	for idx, item := range items {
+		if duration, ok := durations[item]; ok {
+			estimated[idx] = duration
+		} else {
+			estimated[idx] = average * float64(weight(item))
+		}
+	}
+	return estimated
+}
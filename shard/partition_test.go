@@ -0,0 +1,78 @@
+package shard
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPartition(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		items     []string
+		count     int
+		durations map[string]float64
+		weights   map[string]int
+		expected  [][]string
+		totals    []float64
+	}{
+		{
+			name:     "evenly without durations",
+			items:    []string{"A", "B", "C", "D"},
+			count:    2,
+			expected: [][]string{{"A", "C"}, {"B", "D"}},
+			totals:   []float64{2, 2},
+		},
+		{
+			name:      "balanced by durations",
+			items:     []string{"A", "B", "C", "D"},
+			count:     2,
+			durations: map[string]float64{"A": 10, "B": 2, "C": 3, "D": 5},
+			expected:  [][]string{{"A"}, {"B", "C", "D"}},
+			totals:    []float64{10, 10},
+		},
+		{
+			name:      "unknown item estimated with the average",
+			items:     []string{"A", "B", "C"},
+			count:     2,
+			durations: map[string]float64{"A": 4, "B": 2},
+			expected:  [][]string{{"A"}, {"B", "C"}},
+			totals:    []float64{4, 5},
+		},
+		{
+			name:      "unknown whole project estimated with the average of its tests",
+			items:     []string{"project:Sdk", "A", "B", "C"},
+			count:     2,
+			durations: map[string]float64{"A": 2, "B": 2, "C": 2},
+			weights:   map[string]int{"project:Sdk": 3},
+			expected:  [][]string{{"project:Sdk"}, {"A", "B", "C"}},
+			totals:    []float64{6, 6},
+		},
+		{
+			name:      "known whole project counts as its tests in the average",
+			items:     []string{"project:Sdk", "A", "B"},
+			count:     2,
+			durations: map[string]float64{"project:Sdk": 8, "A": 1},
+			weights:   map[string]int{"project:Sdk": 4},
+			expected:  [][]string{{"project:Sdk"}, {"A", "B"}},
+			totals:    []float64{8, 2.8},
+		},
+		{
+			name:     "more shards than items",
+			items:    []string{"A"},
+			count:    3,
+			expected: [][]string{{"A"}, nil, nil},
+			totals:   []float64{1, 0, 0},
+		},
+	} {
+		shards, totals := Partition(tc.items, tc.count, tc.durations, tc.weights)
+		if !reflect.DeepEqual(shards, tc.expected) {
+			t.Errorf("%s: expected shards: %v, got: %v", tc.name, tc.expected, shards)
+		}
+		for i := range tc.totals {
+			if diff := totals[i] - tc.totals[i]; diff > 0.001 || diff < -0.001 {
+				t.Errorf("%s: expected totals: %v, got: %v", tc.name, tc.totals, totals)
+				break
+			}
+		}
+	}
+}
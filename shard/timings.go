@@ -0,0 +1,129 @@
+package shard
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-steplib/steps-nunit-runner/testresult"
+)
+
+// Timings holds the durations (in seconds) of the test cases and of the test fixtures, by their full names,
+// and the timings of the test projects, by their names.
+type Timings struct {
+	Fixtures map[string]float64       `json:"fixtures"`
+	Tests    map[string]float64       `json:"tests"`
+	Projects map[string]ProjectTiming `json:"projects"`
+}
+
+// ProjectTiming holds the duration (in seconds) of a test project, and the number of its test cases and test fixtures.
+type ProjectTiming struct {
+	Duration float64 `json:"duration"`
+	Tests    int     `json:"tests"`
+	Fixtures int     `json:"fixtures"`
+}
+
+// NewTimings ...
+func NewTimings() Timings {
+	return Timings{
+		Fixtures: map[string]float64{},
+		Tests:    map[string]float64{},
+		Projects: map[string]ProjectTiming{},
+	}
+}
+
+// TimingsFromRun collects the durations of the executed test cases of the run,
+// the duration of a fixture is the sum of its test cases.
+func TimingsFromRun(run testresult.TestRun) Timings {
+	timings := NewTimings()
+	for _, testCase := range run.TestCases() {
+		if testCase.Result == testresult.ResultSkipped {
+			continue
+		}
+
+		timings.Tests[testCase.FullName] = testCase.Duration
+		if testCase.ClassName != "" {
+			timings.Fixtures[testCase.ClassName] += testCase.Duration
+		}
+	}
+	return timings
+}
+
+// ProjectTimingFromRun sums the durations of the executed test cases of the run (the run of a single test project),
+// and counts its test cases and test fixtures.
+func ProjectTimingFromRun(run testresult.TestRun) ProjectTiming {
+	timing := ProjectTiming{}
+	fixtures := map[string]bool{}
+	for _, testCase := range run.TestCases() {
+		if testCase.Result == testresult.ResultSkipped {
+			continue
+		}
+
+		timing.Duration += testCase.Duration
+		timing.Tests++
+		if testCase.ClassName != "" && !fixtures[testCase.ClassName] {
+			fixtures[testCase.ClassName] = true
+			timing.Fixtures++
+		}
+	}
+	return timing
+}
+
+// ReadTimings reads a timings file (written by Timings.WriteFile) or a test result file.
+func ReadTimings(pth string) (Timings, error) {
+	content, err := fileutil.ReadBytesFromFile(pth)
+	if err != nil {
+		return Timings{}, fmt.Errorf("Failed to read file (%s), error: %s", pth, err)
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(content), []byte("<")) {
+		run, err := testresult.Parse(content)
+		if err != nil {
+			return Timings{}, fmt.Errorf("Failed to parse test result (%s), error: %s", pth, err)
+		}
+		return TimingsFromRun(run), nil
+	}
+
+	timings := NewTimings()
+	if err := json.Unmarshal(content, &timings); err != nil {
+		return Timings{}, fmt.Errorf("Failed to parse timings file (%s), error: %s", pth, err)
+	}
+	if timings.Fixtures == nil {
+		timings.Fixtures = map[string]float64{}
+	}
+	if timings.Tests == nil {
+		timings.Tests = map[string]float64{}
+	}
+	if timings.Projects == nil {
+		timings.Projects = map[string]ProjectTiming{}
+	}
+	return timings, nil
+}
+
+// Update overrides the timings with the newer ones, the rest of the timings are kept,
+// so the timings of the tests of the other shards are not lost.
+func (timings Timings) Update(newer Timings) {
+	for name, duration := range newer.Fixtures {
+		timings.Fixtures[name] = duration
+	}
+	for name, duration := range newer.Tests {
+		timings.Tests[name] = duration
+	}
+	for name, timing := range newer.Projects {
+		timings.Projects[name] = timing
+	}
+}
+
+// WriteFile ...
+func (timings Timings) WriteFile(pth string) error {
+	content, err := json.MarshalIndent(timings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to serialize timings, error: %s", err)
+	}
+
+	if err := fileutil.WriteBytesToFile(pth, append(content, '\n')); err != nil {
+		return fmt.Errorf("Failed to write timings file (%s), error: %s", pth, err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package testresult
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+const suiteTypeAssembly = "Assembly"
+
+// SelectAssembly returns the run of the given test assembly: the run with the assembly suites of the assembly only.
+// Assembly suites are matched by file name, as the console reports the assemblies with their full paths.
+func (run TestRun) SelectAssembly(assemblyPth string) TestRun {
+	selected := run
+	selected.TestSuites = []TestSuite{}
+	selected.Duration = 0
+
+	for _, suite := range run.TestSuites {
+		for _, assemblySuite := range suite.assemblySuites(filepath.Base(assemblyPth)) {
+			selected.TestSuites = append(selected.TestSuites, assemblySuite)
+			selected.Duration += assemblySuite.Duration
+		}
+	}
+
+	selected.Recount()
+
+	return selected
+}
+
+// assemblySuites returns the assembly suites of the named assembly, from the suite and from its nested suites.
+func (suite TestSuite) assemblySuites(assemblyName string) []TestSuite {
+	if suite.Type == suiteTypeAssembly {
+		if strings.EqualFold(filepath.Base(suite.FullName), assemblyName) || strings.EqualFold(suite.Name, assemblyName) {
+			return []TestSuite{suite}
+		}
+		return nil
+	}
+
+	suites := []TestSuite{}
+	for _, child := range suite.TestSuites {
+		suites = append(suites, child.assemblySuites(assemblyName)...)
+	}
+	return suites
+}
@@ -0,0 +1,104 @@
+package testresult
+
+// Recount recalculates the counters and the results of the run and of its suites from the results of the test cases.
+func (run *TestRun) Recount() {
+	run.Total, run.Passed, run.Failed, run.Warnings, run.Inconclusive, run.Skipped, run.Asserts = 0, 0, 0, 0, 0, 0, 0
+	run.Result = ResultPassed
+
+	for i := range run.TestSuites {
+		suite := &run.TestSuites[i]
+		suite.Recount()
+
+		run.Total += suite.Total
+		run.Passed += suite.Passed
+		run.Failed += suite.Failed
+		run.Warnings += suite.Warnings
+		run.Inconclusive += suite.Inconclusive
+		run.Skipped += suite.Skipped
+		run.Asserts += suite.Asserts
+		run.Result = worseResult(run.Result, suite.Result)
+	}
+
+	run.TestCaseCount = run.Total
+}
+
+// Recount recalculates the counters and the results of the suite and of its nested suites.
+func (suite *TestSuite) Recount() {
+	suite.Total, suite.Passed, suite.Failed, suite.Warnings, suite.Inconclusive, suite.Skipped, suite.Asserts = 0, 0, 0, 0, 0, 0, 0
+
+	// the worst result of the children, the suite is skipped only if every child is skipped
+	result := ""
+
+	for i := range suite.TestSuites {
+		child := &suite.TestSuites[i]
+		child.Recount()
+
+		suite.Total += child.Total
+		suite.Passed += child.Passed
+		suite.Failed += child.Failed
+		suite.Warnings += child.Warnings
+		suite.Inconclusive += child.Inconclusive
+		suite.Skipped += child.Skipped
+		suite.Asserts += child.Asserts
+		result = worseResult(result, child.Result)
+	}
+
+	for _, testCase := range suite.TestCases {
+		suite.Total++
+		suite.Asserts += testCase.Asserts
+
+		switch testCase.Result {
+		case ResultPassed:
+			suite.Passed++
+		case ResultFailed:
+			suite.Failed++
+		case ResultWarning:
+			suite.Warnings++
+		case ResultInconclusive:
+			suite.Inconclusive++
+		case ResultSkipped:
+			suite.Skipped++
+		}
+		result = worseResult(result, testCase.Result)
+	}
+
+	suite.TestCaseCount = suite.Total
+
+	if suite.Result == ResultFailed && suite.Site != "" && suite.Site != "Child" {
+		// the suite itself failed, for example in its OneTimeSetUp
+		return
+	}
+
+	if result != "" {
+		suite.Result = result
+		if result != ResultFailed {
+			if result != ResultSkipped {
+				suite.Label = ""
+			}
+			if suite.Site == "Child" {
+				suite.Site = ""
+				suite.Failure = nil
+			}
+		}
+	}
+}
+
+// resultSeverity ranks the results, the skipped (ignored) tests are neutral:
+// they do not make a suite or a run with passed tests worse than passed.
+var resultSeverity = map[string]int{
+	ResultSkipped:      0,
+	ResultPassed:       1,
+	ResultInconclusive: 2,
+	ResultWarning:      3,
+	ResultFailed:       4,
+}
+
+func worseResult(a, b string) string {
+	if a == "" {
+		return b
+	}
+	if resultSeverity[b] > resultSeverity[a] {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,97 @@
+package testresult
+
+import "testing"
+
+func testFixture(testCases ...TestCase) TestSuite {
+	return TestSuite{Type: "TestFixture", Name: "Fixture", FullName: "Fixture", Result: ResultFailed, Site: "Child", TestCases: testCases}
+}
+
+func TestRecount(t *testing.T) {
+	run := TestRun{
+		TestSuites: []TestSuite{
+			{
+				Type: "Assembly",
+				TestSuites: []TestSuite{
+					testFixture(
+						TestCase{FullName: "Fixture.A", Result: ResultPassed, Asserts: 1},
+						TestCase{FullName: "Fixture.B", Result: ResultFailed, Asserts: 2},
+						TestCase{FullName: "Fixture.C", Result: ResultInconclusive},
+					),
+					testFixture(
+						TestCase{FullName: "Fixture.D", Result: ResultWarning},
+						TestCase{FullName: "Fixture.E", Result: ResultSkipped, Label: LabelIgnored},
+					),
+				},
+			},
+		},
+	}
+
+	run.Recount()
+
+	if run.Total != 5 || run.TestCaseCount != 5 || run.Passed != 1 || run.Failed != 1 || run.Inconclusive != 1 || run.Warnings != 1 || run.Skipped != 1 || run.Asserts != 3 {
+		t.Fatalf("unexpected counters: %+v", run)
+	}
+	if run.Result != ResultFailed || run.TestSuites[0].Result != ResultFailed {
+		t.Fatalf("expected failed run and assembly, got: %s, %s", run.Result, run.TestSuites[0].Result)
+	}
+
+	second := run.TestSuites[0].TestSuites[1]
+	if second.Result != ResultWarning || second.Site != "" || second.Label != "" {
+		t.Fatalf("expected the second fixture to have a warning, without the failure site, got: %s site: %s", second.Result, second.Site)
+	}
+}
+
+func TestRecountAfterFix(t *testing.T) {
+	fixture := testFixture(
+		TestCase{FullName: "Fixture.A", Result: ResultPassed},
+		TestCase{FullName: "Fixture.B", Result: ResultFailed},
+	)
+	fixture.Failure = &Failure{Message: "One or more child tests had errors"}
+	run := TestRun{TestSuites: []TestSuite{fixture}}
+
+	run.TestSuites[0].TestCases[1].Result = ResultPassed
+	run.Recount()
+
+	if run.Result != ResultPassed || run.Passed != 2 || run.Failed != 0 {
+		t.Fatalf("expected a passed run, got: %s passed: %d failed: %d", run.Result, run.Passed, run.Failed)
+	}
+	if suite := run.TestSuites[0]; suite.Result != ResultPassed || suite.Site != "" || suite.Failure != nil {
+		t.Fatalf("expected the child failure of the fixture to be cleared, got: %s site: %s failure: %+v", suite.Result, suite.Site, suite.Failure)
+	}
+}
+
+func TestRecountIgnoredTestIsNeutral(t *testing.T) {
+	fixture := testFixture(
+		TestCase{FullName: "Fixture.A", Result: ResultSkipped, Label: LabelIgnored},
+		TestCase{FullName: "Fixture.B", Result: ResultFailed},
+	)
+	ignored := TestSuite{Type: "TestFixture", Name: "Ignored", FullName: "Ignored", Result: ResultSkipped, Label: LabelIgnored, TestCases: []TestCase{
+		{FullName: "Ignored.A", Result: ResultSkipped, Label: LabelIgnored},
+	}}
+	run := TestRun{TestSuites: []TestSuite{fixture, ignored}}
+
+	run.TestSuites[0].TestCases[1].Result = ResultPassed
+	run.Recount()
+
+	if run.Result != ResultPassed || run.Passed != 1 || run.Skipped != 2 {
+		t.Fatalf("expected a passed run, got: %s passed: %d skipped: %d", run.Result, run.Passed, run.Skipped)
+	}
+	if run.TestSuites[0].Result != ResultPassed {
+		t.Fatalf("expected the fixture with a passed and an ignored test to pass, got: %s", run.TestSuites[0].Result)
+	}
+	if run.TestSuites[1].Result != ResultSkipped || run.TestSuites[1].Label != LabelIgnored {
+		t.Fatalf("expected the fixture with ignored tests only to be skipped, got: %s label: %s", run.TestSuites[1].Result, run.TestSuites[1].Label)
+	}
+}
+
+func TestRecountKeepsSetUpFailure(t *testing.T) {
+	fixture := testFixture(TestCase{FullName: "Fixture.A", Result: ResultPassed})
+	fixture.Site = "SetUp"
+	run := TestRun{TestSuites: []TestSuite{fixture}}
+
+	run.Recount()
+
+	if run.TestSuites[0].Result != ResultFailed || run.Result != ResultFailed {
+		t.Fatalf("expected the fixture failed in its setup to stay failed, got: %s, run: %s", run.TestSuites[0].Result, run.Result)
+	}
+}
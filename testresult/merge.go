@@ -0,0 +1,37 @@
+package testresult
+
+// Merge merges the given runs into a single run, which contains the test suites of every run.
+func Merge(runs ...TestRun) TestRun {
+	merged := TestRun{
+		Result:     ResultPassed,
+		TestSuites: []TestSuite{},
+	}
+
+	for i, run := range runs {
+		if i == 0 {
+			merged.ID = run.ID
+			merged.EngineVersion = run.EngineVersion
+			merged.ClrVersion = run.ClrVersion
+			merged.StartTime = run.StartTime
+			merged.CommandLine = run.CommandLine
+			merged.ExtraAttrs = run.ExtraAttrs
+			merged.Extra = run.Extra
+		}
+		merged.EndTime = run.EndTime
+
+		merged.TestCaseCount += run.TestCaseCount
+		merged.Total += run.Total
+		merged.Passed += run.Passed
+		merged.Failed += run.Failed
+		merged.Warnings += run.Warnings
+		merged.Inconclusive += run.Inconclusive
+		merged.Skipped += run.Skipped
+		merged.Asserts += run.Asserts
+		merged.Duration += run.Duration
+
+		merged.Result = worseResult(merged.Result, run.Result)
+		merged.TestSuites = append(merged.TestSuites, run.TestSuites...)
+	}
+
+	return merged
+}
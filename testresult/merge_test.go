@@ -0,0 +1,51 @@
+package testresult
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	nunit3Run, err := ParseFile(filepath.Join("testdata", "nunit3.xml"))
+	if err != nil {
+		t.Fatalf("Failed to parse, error: %s", err)
+	}
+	nunit2Run, err := ParseFile(filepath.Join("testdata", "nunit2.xml"))
+	if err != nil {
+		t.Fatalf("Failed to parse, error: %s", err)
+	}
+
+	merged := Merge(nunit3Run, nunit2Run)
+
+	if merged.Result != ResultFailed {
+		t.Fatalf("expected result: %s, got: %s", ResultFailed, merged.Result)
+	}
+	if merged.Total != 7 || merged.Passed != 3 || merged.Failed != 2 || merged.Skipped != 2 {
+		t.Fatalf("unexpected counters, total: %d passed: %d failed: %d skipped: %d", merged.Total, merged.Passed, merged.Failed, merged.Skipped)
+	}
+	if len(merged.TestSuites) != 2 || len(merged.TestCases()) != 7 {
+		t.Fatalf("expected the suites of both runs, got: %d suites, %d test cases", len(merged.TestSuites), len(merged.TestCases()))
+	}
+	if merged.EngineVersion != nunit3Run.EngineVersion || merged.StartTime != nunit3Run.StartTime || merged.CommandLine != nunit3Run.CommandLine {
+		t.Fatalf("expected the run attributes of the first run")
+	}
+	if len(merged.Extra) != 1 || merged.Extra[0].XMLName.Local != "filter" {
+		t.Fatalf("expected the filter of the first run, got: %+v", merged.Extra)
+	}
+	if merged.Duration != nunit3Run.Duration+nunit2Run.Duration {
+		t.Fatalf("expected the sum of the durations, got: %f", merged.Duration)
+	}
+}
+
+func TestMergePassed(t *testing.T) {
+	passed := TestRun{Result: ResultPassed, Total: 1, Passed: 1}
+
+	merged := Merge(passed, passed)
+	if merged.Result != ResultPassed || merged.Total != 2 || merged.Passed != 2 {
+		t.Fatalf("unexpected merged run: %s total: %d passed: %d", merged.Result, merged.Total, merged.Passed)
+	}
+
+	if empty := Merge(); empty.Result != ResultPassed || len(empty.TestSuites) != 0 {
+		t.Fatalf("unexpected empty merge: %+v", empty)
+	}
+}
@@ -0,0 +1,213 @@
+package testresult
+
+import "encoding/xml"
+
+// Test results and labels, as used by the NUnit 3 result format.
+const (
+	ResultPassed       = "Passed"
+	ResultFailed       = "Failed"
+	ResultWarning      = "Warning"
+	ResultInconclusive = "Inconclusive"
+	ResultSkipped      = "Skipped"
+
+	LabelError     = "Error"
+	LabelInvalid   = "Invalid"
+	LabelCancelled = "Cancelled"
+	LabelIgnored   = "Ignored"
+	LabelExplicit  = "Explicit"
+)
+
+const categoryPropertyName = "Category"
+
+// TestRun ...
+type TestRun struct {
+	XMLName xml.Name `xml:"test-run"`
+
+	ID            string  `xml:"id,attr,omitempty"`
+	TestCaseCount int     `xml:"testcasecount,attr"`
+	Result        string  `xml:"result,attr"`
+	Label         string  `xml:"label,attr,omitempty"`
+	Total         int     `xml:"total,attr"`
+	Passed        int     `xml:"passed,attr"`
+	Failed        int     `xml:"failed,attr"`
+	Warnings      int     `xml:"warnings,attr"`
+	Inconclusive  int     `xml:"inconclusive,attr"`
+	Skipped       int     `xml:"skipped,attr"`
+	Asserts       int     `xml:"asserts,attr"`
+	EngineVersion string  `xml:"engine-version,attr,omitempty"`
+	ClrVersion    string  `xml:"clr-version,attr,omitempty"`
+	StartTime     string  `xml:"start-time,attr,omitempty"`
+	EndTime       string  `xml:"end-time,attr,omitempty"`
+	Duration      float64 `xml:"duration,attr"`
+
+	ExtraAttrs []xml.Attr `xml:",any,attr"`
+
+	CommandLine CData        `xml:"command-line,omitempty"`
+	Extra       []RawElement `xml:",any"`
+	TestSuites  []TestSuite  `xml:"test-suite"`
+}
+
+// TestSuite ...
+type TestSuite struct {
+	Type          string  `xml:"type,attr"`
+	ID            string  `xml:"id,attr,omitempty"`
+	Name          string  `xml:"name,attr"`
+	FullName      string  `xml:"fullname,attr"`
+	ClassName     string  `xml:"classname,attr,omitempty"`
+	RunState      string  `xml:"runstate,attr,omitempty"`
+	TestCaseCount int     `xml:"testcasecount,attr"`
+	Result        string  `xml:"result,attr"`
+	Label         string  `xml:"label,attr,omitempty"`
+	Site          string  `xml:"site,attr,omitempty"`
+	StartTime     string  `xml:"start-time,attr,omitempty"`
+	EndTime       string  `xml:"end-time,attr,omitempty"`
+	Duration      float64 `xml:"duration,attr"`
+	Total         int     `xml:"total,attr"`
+	Passed        int     `xml:"passed,attr"`
+	Failed        int     `xml:"failed,attr"`
+	Warnings      int     `xml:"warnings,attr"`
+	Inconclusive  int     `xml:"inconclusive,attr"`
+	Skipped       int     `xml:"skipped,attr"`
+	Asserts       int     `xml:"asserts,attr"`
+
+	ExtraAttrs []xml.Attr `xml:",any,attr"`
+
+	// the elements not modeled, for example the environment and the settings of the assembly suites
+	Extra       []RawElement `xml:",any"`
+	Properties  Properties   `xml:"properties,omitempty"`
+	Reason      *Reason      `xml:"reason"`
+	Failure     *Failure     `xml:"failure"`
+	Output      CData        `xml:"output,omitempty"`
+	TestSuites  []TestSuite  `xml:"test-suite"`
+	TestCases   []TestCase   `xml:"test-case"`
+	Attachments Attachments  `xml:"attachments,omitempty"`
+}
+
+// TestCase ...
+type TestCase struct {
+	ID         string  `xml:"id,attr,omitempty"`
+	Name       string  `xml:"name,attr"`
+	FullName   string  `xml:"fullname,attr"`
+	MethodName string  `xml:"methodname,attr,omitempty"`
+	ClassName  string  `xml:"classname,attr,omitempty"`
+	RunState   string  `xml:"runstate,attr,omitempty"`
+	Seed       string  `xml:"seed,attr,omitempty"`
+	Result     string  `xml:"result,attr"`
+	Label      string  `xml:"label,attr,omitempty"`
+	Site       string  `xml:"site,attr,omitempty"`
+	StartTime  string  `xml:"start-time,attr,omitempty"`
+	EndTime    string  `xml:"end-time,attr,omitempty"`
+	Duration   float64 `xml:"duration,attr"`
+	Asserts    int     `xml:"asserts,attr"`
+
+	ExtraAttrs []xml.Attr `xml:",any,attr"`
+
+	Properties Properties `xml:"properties,omitempty"`
+	Reason     *Reason    `xml:"reason"`
+	Failure    *Failure   `xml:"failure"`
+	Output     CData      `xml:"output,omitempty"`
+	// the elements not modeled, for example the assertions of the test case
+	Extra       []RawElement `xml:",any"`
+	Attachments Attachments  `xml:"attachments,omitempty"`
+}
+
+// Properties ...
+type Properties []Property
+
+// Property ...
+type Property struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// Reason ...
+type Reason struct {
+	Message string `xml:"message"`
+}
+
+// Failure ...
+type Failure struct {
+	Message    string `xml:"message,omitempty"`
+	StackTrace string `xml:"stack-trace,omitempty"`
+}
+
+// Attachments ...
+type Attachments []Attachment
+
+// CData is a text, written as a CDATA section, the same way as the consoles write the messages and the outputs.
+type CData string
+
+// RawElement is an element of the result, which is not modeled, but kept as it is,
+// so that rewriting a result does not drop the content of the console result.
+type RawElement struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	InnerXML string     `xml:",innerxml"`
+}
+
+// Attachment ...
+type Attachment struct {
+	FilePath    string `xml:"filePath"`
+	Description string `xml:"description,omitempty"`
+}
+
+// TestCases returns every test case of the run, in document order.
+func (run TestRun) TestCases() []TestCase {
+	testCases := []TestCase{}
+	for _, suite := range run.TestSuites {
+		testCases = append(testCases, suite.AllTestCases()...)
+	}
+	return testCases
+}
+
+// AllTestCases returns the test cases of the suite and of its nested suites.
+func (suite TestSuite) AllTestCases() []TestCase {
+	testCases := append([]TestCase{}, suite.TestCases...)
+	for _, child := range suite.TestSuites {
+		testCases = append(testCases, child.AllTestCases()...)
+	}
+	return testCases
+}
+
+// Categories ...
+func (suite TestSuite) Categories() []string {
+	return propertyValues(suite.Properties, categoryPropertyName)
+}
+
+// Categories ...
+func (testCase TestCase) Categories() []string {
+	return propertyValues(testCase.Properties, categoryPropertyName)
+}
+
+// Property returns the first value of the named property.
+func (testCase TestCase) Property(name string) (string, bool) {
+	for _, property := range testCase.Properties {
+		if property.Name == name {
+			return property.Value, true
+		}
+	}
+	return "", false
+}
+
+// IsFailed ...
+func (testCase TestCase) IsFailed() bool {
+	return testCase.Result == ResultFailed
+}
+
+// IsError reports whether the test case failed with an unexpected exception or could not be run.
+func (testCase TestCase) IsError() bool {
+	if testCase.Result != ResultFailed {
+		return false
+	}
+	return testCase.Label == LabelError || testCase.Label == LabelInvalid || testCase.Label == LabelCancelled
+}
+
+func propertyValues(properties Properties, name string) []string {
+	values := []string{}
+	for _, property := range properties {
+		if property.Name == name {
+			values = append(values, property.Value)
+		}
+	}
+	return values
+}
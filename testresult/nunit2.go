@@ -0,0 +1,218 @@
+package testresult
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+)
+
+type nunit2TestResults struct {
+	XMLName xml.Name `xml:"test-results"`
+
+	Name string `xml:"name,attr"`
+	Date string `xml:"date,attr"`
+	Time string `xml:"time,attr"`
+
+	Environment struct {
+		NUnitVersion string `xml:"nunit-version,attr"`
+		ClrVersion   string `xml:"clr-version,attr"`
+	} `xml:"environment"`
+
+	TestSuite nunit2TestSuite `xml:"test-suite"`
+}
+
+type nunit2Category struct {
+	Name string `xml:"name,attr"`
+}
+
+type nunit2TestSuite struct {
+	Type        string `xml:"type,attr"`
+	Name        string `xml:"name,attr"`
+	Description string `xml:"description,attr"`
+	Executed    string `xml:"executed,attr"`
+	Result      string `xml:"result,attr"`
+	Success     string `xml:"success,attr"`
+	Time        string `xml:"time,attr"`
+	Asserts     int    `xml:"asserts,attr"`
+
+	Categories []nunit2Category `xml:"categories>category"`
+	Properties []Property       `xml:"properties>property"`
+	Reason     *Reason          `xml:"reason"`
+	Failure    *Failure         `xml:"failure"`
+
+	TestSuites []nunit2TestSuite `xml:"results>test-suite"`
+	TestCases  []nunit2TestCase  `xml:"results>test-case"`
+}
+
+type nunit2TestCase struct {
+	Name        string `xml:"name,attr"`
+	Description string `xml:"description,attr"`
+	Executed    string `xml:"executed,attr"`
+	Result      string `xml:"result,attr"`
+	Success     string `xml:"success,attr"`
+	Time        string `xml:"time,attr"`
+	Asserts     int    `xml:"asserts,attr"`
+
+	Categories []nunit2Category `xml:"categories>category"`
+	Properties []Property       `xml:"properties>property"`
+	Reason     *Reason          `xml:"reason"`
+	Failure    *Failure         `xml:"failure"`
+}
+
+func parseNUnit2(content []byte) (TestRun, error) {
+	var results nunit2TestResults
+	if err := xml.Unmarshal(content, &results); err != nil {
+		return TestRun{}, err
+	}
+
+	run := TestRun{
+		EngineVersion: results.Environment.NUnitVersion,
+		ClrVersion:    results.Environment.ClrVersion,
+		StartTime:     strings.TrimSpace(results.Date + " " + results.Time),
+		Duration:      parseNUnit2Time(results.TestSuite.Time),
+		TestSuites:    []TestSuite{convertNUnit2TestSuite(results.TestSuite, "", "")},
+	}
+	run.Recount()
+
+	return run, nil
+}
+
+// convertNUnit2TestSuite converts a NUnit 2 suite into the NUnit 3 model.
+// NUnit 2 suites only store their own name, the full name is built from the names of the enclosing namespaces and fixtures.
+func convertNUnit2TestSuite(suite nunit2TestSuite, parentType, parentFullName string) TestSuite {
+	converted := TestSuite{
+		Name:       suite.Name,
+		FullName:   suite.Name,
+		Duration:   parseNUnit2Time(suite.Time),
+		Asserts:    suite.Asserts,
+		Properties: convertNUnit2Properties(suite.Properties, suite.Categories, suite.Description),
+		Reason:     suite.Reason,
+		Failure:    suite.Failure,
+	}
+
+	switch suite.Type {
+	case "Namespace":
+		converted.Type = "TestSuite"
+	case "ParameterizedTest":
+		converted.Type = "ParameterizedMethod"
+	case "Test Project":
+		converted.Type = "Project"
+	default:
+		converted.Type = suite.Type
+	}
+
+	if parentType == "Namespace" || parentType == "TestFixture" || parentType == "GenericFixture" {
+		converted.FullName = parentFullName + "." + suite.Name
+	}
+	if suite.Type == "TestFixture" {
+		converted.ClassName = converted.FullName
+	}
+
+	className := ""
+	if suite.Type == "TestFixture" || suite.Type == "GenericFixture" {
+		className = converted.FullName
+	}
+
+	for _, child := range suite.TestSuites {
+		converted.TestSuites = append(converted.TestSuites, convertNUnit2TestSuite(child, suite.Type, converted.FullName))
+	}
+	for _, testCase := range suite.TestCases {
+		converted.TestCases = append(converted.TestCases, convertNUnit2TestCase(testCase, className))
+	}
+
+	converted.Result, converted.Label = convertNUnit2Result(suite.Result, suite.Executed)
+	if converted.Result == ResultFailed {
+		converted.Site = "SetUp"
+		for _, testCase := range converted.AllTestCases() {
+			if testCase.IsFailed() {
+				converted.Site = "Child"
+				break
+			}
+		}
+	}
+
+	return converted
+}
+
+// convertNUnit2TestCase converts a NUnit 2 test case into the NUnit 3 model.
+// NUnit 2 test case names are fully qualified, like: Namespace.Fixture.Method(args).
+func convertNUnit2TestCase(testCase nunit2TestCase, className string) TestCase {
+	name := testCase.Name
+	methodPart := name
+	if idx := strings.Index(name, "("); idx > -1 {
+		methodPart = name[:idx]
+	}
+	if idx := strings.LastIndex(methodPart, "."); idx > -1 {
+		name = testCase.Name[idx+1:]
+		if className == "" {
+			className = testCase.Name[:idx]
+		}
+	}
+
+	methodName := name
+	if idx := strings.Index(methodName, "("); idx > -1 {
+		methodName = methodName[:idx]
+	}
+
+	converted := TestCase{
+		Name:       name,
+		FullName:   testCase.Name,
+		MethodName: methodName,
+		ClassName:  className,
+		Duration:   parseNUnit2Time(testCase.Time),
+		Asserts:    testCase.Asserts,
+		Properties: convertNUnit2Properties(testCase.Properties, testCase.Categories, testCase.Description),
+		Reason:     testCase.Reason,
+		Failure:    testCase.Failure,
+	}
+	converted.Result, converted.Label = convertNUnit2Result(testCase.Result, testCase.Executed)
+
+	return converted
+}
+
+// convertNUnit2Result maps a NUnit 2 result state to a NUnit 3 result and label.
+func convertNUnit2Result(result, executed string) (string, string) {
+	switch result {
+	case "Success":
+		return ResultPassed, ""
+	case "Failure":
+		return ResultFailed, ""
+	case "Error":
+		return ResultFailed, LabelError
+	case "Cancelled":
+		return ResultFailed, LabelCancelled
+	case "NotRunnable":
+		return ResultFailed, LabelInvalid
+	case "Ignored":
+		return ResultSkipped, LabelIgnored
+	case "Skipped":
+		return ResultSkipped, ""
+	case "Inconclusive":
+		return ResultInconclusive, ""
+	}
+
+	if strings.EqualFold(executed, "false") {
+		return ResultSkipped, ""
+	}
+	return "", ""
+}
+
+func convertNUnit2Properties(properties []Property, categories []nunit2Category, description string) Properties {
+	var converted Properties
+	if description != "" {
+		converted = append(converted, Property{Name: "Description", Value: description})
+	}
+	for _, category := range categories {
+		converted = append(converted, Property{Name: categoryPropertyName, Value: category.Name})
+	}
+	return append(converted, properties...)
+}
+
+// parseNUnit2Time parses the time attribute, which is written with the culture of the test run (0.123 or 0,123).
+func parseNUnit2Time(value string) float64 {
+	duration, err := strconv.ParseFloat(strings.Replace(value, ",", ".", -1), 64)
+	if err != nil {
+		return 0
+	}
+	return duration
+}
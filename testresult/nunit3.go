@@ -0,0 +1,77 @@
+package testresult
+
+import "encoding/xml"
+
+func parseNUnit3(content []byte) (TestRun, error) {
+	var run TestRun
+	if err := xml.Unmarshal(content, &run); err != nil {
+		return TestRun{}, err
+	}
+	return run, nil
+}
+
+type cdataElement struct {
+	Text string `xml:",cdata"`
+}
+
+// MarshalXML ...
+func (text CData) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(cdataElement{Text: string(text)}, start)
+}
+
+type reasonElement struct {
+	Message CData `xml:"message,omitempty"`
+}
+
+// MarshalXML ...
+func (reason Reason) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(reasonElement{Message: CData(reason.Message)}, start)
+}
+
+type failureElement struct {
+	Message    CData `xml:"message,omitempty"`
+	StackTrace CData `xml:"stack-trace,omitempty"`
+}
+
+// MarshalXML ...
+func (failure Failure) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(failureElement{Message: CData(failure.Message), StackTrace: CData(failure.StackTrace)}, start)
+}
+
+type propertiesElement struct {
+	Properties []Property `xml:"property"`
+}
+
+// MarshalXML ...
+func (properties Properties) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(propertiesElement{Properties: properties}, start)
+}
+
+// UnmarshalXML ...
+func (properties *Properties) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var element propertiesElement
+	if err := d.DecodeElement(&element, &start); err != nil {
+		return err
+	}
+	*properties = append(*properties, element.Properties...)
+	return nil
+}
+
+type attachmentsElement struct {
+	Attachments []Attachment `xml:"attachment"`
+}
+
+// MarshalXML ...
+func (attachments Attachments) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(attachmentsElement{Attachments: attachments}, start)
+}
+
+// UnmarshalXML ...
+func (attachments *Attachments) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var element attachmentsElement
+	if err := d.DecodeElement(&element, &start); err != nil {
+		return err
+	}
+	*attachments = append(*attachments, element.Attachments...)
+	return nil
+}
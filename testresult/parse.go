@@ -0,0 +1,90 @@
+package testresult
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/bitrise-io/go-utils/fileutil"
+)
+
+// Format ...
+type Format string
+
+const (
+	// FormatNUnit3 ...
+	FormatNUnit3 Format = "nunit3"
+	// FormatNUnit2 ...
+	FormatNUnit2 Format = "nunit2"
+	// FormatTRX ...
+	FormatTRX Format = "trx"
+)
+
+// DetectFormat detects the result format, based on the root element of the document.
+func DetectFormat(content []byte) (Format, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return "", fmt.Errorf("no root element found")
+		} else if err != nil {
+			return "", err
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			switch start.Name.Local {
+			case "test-run":
+				return FormatNUnit3, nil
+			case "test-results":
+				return FormatNUnit2, nil
+			case "TestRun":
+				return FormatTRX, nil
+			default:
+				return "", fmt.Errorf("unknown test result format, root element: %s", start.Name.Local)
+			}
+		}
+	}
+}
+
+// ParseFile ...
+func ParseFile(pth string) (TestRun, error) {
+	content, err := fileutil.ReadBytesFromFile(pth)
+	if err != nil {
+		return TestRun{}, fmt.Errorf("Failed to read file (%s), error: %s", pth, err)
+	}
+
+	run, err := Parse(content)
+	if err != nil {
+		return TestRun{}, fmt.Errorf("Failed to parse test result (%s), error: %s", pth, err)
+	}
+
+	return run, nil
+}
+
+// Parse parses the content of a NUnit 3, NUnit 2 or TRX result file.
+// NUnit 2 and TRX results are normalized into the NUnit 3 model.
+func Parse(content []byte) (TestRun, error) {
+	format, err := DetectFormat(content)
+	if err != nil {
+		return TestRun{}, err
+	}
+
+	switch format {
+	case FormatNUnit2:
+		return parseNUnit2(content)
+	case FormatTRX:
+		return parseTRX(content)
+	}
+	return parseNUnit3(content)
+}
+
+// WriteFile writes the run to the given path, in NUnit 3 result format.
+func (run TestRun) WriteFile(pth string) error {
+	content, err := xml.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to serialize test result, error: %s", err)
+	}
+
+	return fileutil.WriteStringToFile(pth, xml.Header+string(content))
+}
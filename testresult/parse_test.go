@@ -0,0 +1,216 @@
+package testresult
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseNUnit3(t *testing.T) {
+	run, err := ParseFile(filepath.Join("testdata", "nunit3.xml"))
+	if err != nil {
+		t.Fatalf("Failed to parse, error: %s", err)
+	}
+
+	if run.Result != ResultFailed || run.Total != 4 || run.Passed != 2 || run.Failed != 1 || run.Skipped != 1 {
+		t.Fatalf("unexpected run counters: %s total: %d passed: %d failed: %d skipped: %d", run.Result, run.Total, run.Passed, run.Failed, run.Skipped)
+	}
+	if run.EngineVersion != "3.10.0.0" {
+		t.Fatalf("unexpected engine version: %s", run.EngineVersion)
+	}
+
+	testCases := run.TestCases()
+	names := []string{}
+	for _, testCase := range testCases {
+		names = append(names, testCase.FullName)
+	}
+	expectedNames := []string{
+		"Calculator.CalculatorTests.Add",
+		"Calculator.CalculatorTests.Divide",
+		"Calculator.CalculatorTests.Multiply(2,3)",
+		"Calculator.CalculatorTests.Subtract",
+	}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Fatalf("expected test cases: %v, got: %v", expectedNames, names)
+	}
+
+	divide := testCases[1]
+	if !divide.IsFailed() || divide.IsError() {
+		t.Fatalf("expected Divide to fail with an assertion, got: %s (%s)", divide.Result, divide.Label)
+	}
+	if divide.Failure == nil || !strings.Contains(divide.Failure.Message, "But was:  3") || !strings.Contains(divide.Failure.StackTrace, "CalculatorTests.cs:line 21") {
+		t.Fatalf("unexpected failure of Divide: %+v", divide.Failure)
+	}
+
+	if categories := testCases[2].Categories(); !reflect.DeepEqual(categories, []string{"Fast"}) {
+		t.Fatalf("unexpected categories of Multiply: %v", categories)
+	}
+
+	subtract := testCases[3]
+	if subtract.Result != ResultSkipped || subtract.Label != LabelIgnored || subtract.Reason == nil || subtract.Reason.Message != "not implemented" {
+		t.Fatalf("unexpected Subtract: %s (%s) %+v", subtract.Result, subtract.Label, subtract.Reason)
+	}
+}
+
+func TestParseNUnit2(t *testing.T) {
+	run, err := ParseFile(filepath.Join("testdata", "nunit2.xml"))
+	if err != nil {
+		t.Fatalf("Failed to parse, error: %s", err)
+	}
+
+	if run.Result != ResultFailed || run.Total != 3 || run.Passed != 1 || run.Failed != 1 || run.Skipped != 1 {
+		t.Fatalf("unexpected run counters: %s total: %d passed: %d failed: %d skipped: %d", run.Result, run.Total, run.Passed, run.Failed, run.Skipped)
+	}
+	if run.EngineVersion != "2.6.4.14350" {
+		t.Fatalf("unexpected engine version: %s", run.EngineVersion)
+	}
+
+	testCases := run.TestCases()
+	if len(testCases) != 3 {
+		t.Fatalf("expected 3 test cases, got: %d", len(testCases))
+	}
+
+	add := testCases[0]
+	if add.Name != "Add" || add.FullName != "Calculator.CalculatorTests.Add" || add.ClassName != "Calculator.CalculatorTests" || add.Duration != 0.05 {
+		t.Fatalf("unexpected Add: %+v", add)
+	}
+
+	fixture := run.TestSuites[0].TestSuites[0].TestSuites[0]
+	if fixture.Type != "TestFixture" || fixture.FullName != "Calculator.CalculatorTests" || fixture.Site != "Child" {
+		t.Fatalf("unexpected fixture: %s %s site: %s", fixture.Type, fixture.FullName, fixture.Site)
+	}
+	if categories := fixture.Categories(); !reflect.DeepEqual(categories, []string{"Unit"}) {
+		t.Fatalf("unexpected categories of the fixture: %v", categories)
+	}
+
+	if subtract := testCases[2]; subtract.Result != ResultSkipped || subtract.Label != LabelIgnored {
+		t.Fatalf("unexpected Subtract: %s (%s)", subtract.Result, subtract.Label)
+	}
+}
+
+func TestParseTRX(t *testing.T) {
+	run, err := ParseFile(filepath.Join("testdata", "dotnet.trx"))
+	if err != nil {
+		t.Fatalf("Failed to parse, error: %s", err)
+	}
+
+	if run.Result != ResultFailed || run.Total != 12 || run.Passed != 2 || run.Failed != 5 || run.Skipped != 2 {
+		t.Fatalf("unexpected run counters: %s total: %d passed: %d failed: %d skipped: %d", run.Result, run.Total, run.Passed, run.Failed, run.Skipped)
+	}
+
+	assembly := run.TestSuites[0]
+	if assembly.Type != "Assembly" || assembly.Name != "Calculator.Tests.dll" || len(assembly.TestSuites) != 1 {
+		t.Fatalf("unexpected assembly suite: %s %s with %d fixture(s)", assembly.Type, assembly.Name, len(assembly.TestSuites))
+	}
+
+	testCases := map[string]TestCase{}
+	for _, testCase := range run.TestCases() {
+		testCases[testCase.Name] = testCase
+	}
+
+	for _, tc := range []struct {
+		outcome string
+		result  string
+		label   string
+	}{
+		{"Passed", ResultPassed, ""},
+		{"PassedButRunAborted", ResultPassed, ""},
+		{"Failed", ResultFailed, ""},
+		{"Error", ResultFailed, LabelError},
+		{"Timeout", ResultFailed, LabelError},
+		{"Aborted", ResultFailed, LabelError},
+		{"NotRunnable", ResultFailed, LabelError},
+		{"NotExecuted", ResultSkipped, LabelIgnored},
+		{"Disconnected", ResultSkipped, LabelIgnored},
+		{"Warning", ResultWarning, ""},
+		{"Inconclusive", ResultInconclusive, ""},
+		{"Pending", ResultInconclusive, ""},
+	} {
+		testCase, ok := testCases[tc.outcome]
+		if !ok {
+			t.Errorf("%s: test case not found", tc.outcome)
+			continue
+		}
+		if testCase.Result != tc.result || testCase.Label != tc.label {
+			t.Errorf("%s: expected: %s (%s), got: %s (%s)", tc.outcome, tc.result, tc.label, testCase.Result, testCase.Label)
+		}
+		if testCase.FullName != "Calculator.Tests.OutcomeTests."+tc.outcome || testCase.ClassName != "Calculator.Tests.OutcomeTests" {
+			t.Errorf("%s: unexpected names: %s %s", tc.outcome, testCase.FullName, testCase.ClassName)
+		}
+	}
+
+	notRunnable := testCases["NotRunnable"]
+	if !notRunnable.IsError() || notRunnable.Failure == nil || !strings.Contains(notRunnable.Failure.Message, "non-void return value") {
+		t.Fatalf("unexpected NotRunnable: %s (%s) %+v", notRunnable.Result, notRunnable.Label, notRunnable.Failure)
+	}
+
+	failed := testCases["Failed"]
+	if failed.Duration != 1.01 || failed.Failure == nil || !strings.Contains(failed.Failure.StackTrace, "OutcomeTests.cs:line 11") {
+		t.Fatalf("unexpected Failed: %v %+v", failed.Duration, failed.Failure)
+	}
+
+	notExecuted := testCases["NotExecuted"]
+	if notExecuted.Reason == nil || notExecuted.Reason.Message != "not implemented" {
+		t.Fatalf("unexpected reason of NotExecuted: %+v", notExecuted.Reason)
+	}
+}
+
+func TestParseUnknownFormat(t *testing.T) {
+	if _, err := Parse([]byte(`<?xml version="1.0"?><testsuites />`)); err == nil {
+		t.Fatalf("expected an error for an unknown root element")
+	}
+	if _, err := Parse([]byte(``)); err == nil {
+		t.Fatalf("expected an error for an empty document")
+	}
+}
+
+func TestWriteFileRoundTrip(t *testing.T) {
+	run, err := ParseFile(filepath.Join("testdata", "nunit3.xml"))
+	if err != nil {
+		t.Fatalf("Failed to parse, error: %s", err)
+	}
+
+	tmpDir, err := ioutil.TempDir("", "testresult")
+	if err != nil {
+		t.Fatalf("Failed to create tmp dir, error: %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	pth := filepath.Join(tmpDir, "TestResult.xml")
+	if err := run.WriteFile(pth); err != nil {
+		t.Fatalf("Failed to write, error: %s", err)
+	}
+
+	content, err := ioutil.ReadFile(pth)
+	if err != nil {
+		t.Fatalf("Failed to read, error: %s", err)
+	}
+
+	// the elements and the attributes not modeled, and the CDATA sections are kept
+	for _, expected := range []string{
+		`random-seed="1234"`,
+		`<cat>Slow</cat>`,
+		`<environment framework-version="3.11.0.0"`,
+		`<setting name="NumberOfTestWorkers" value="4" />`,
+		`<assertion result="Failed">`,
+		`<command-line><![CDATA["nunit3-console.exe" "Calculator.Tests.dll" --where "cat != Slow"]]></command-line>`,
+		`<output><![CDATA[adding <1> & <2>`,
+		`<stack-trace><![CDATA[at Calculator.CalculatorTests.Divide()`,
+		`<reason>`,
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("expected the written result to contain: %s", expected)
+		}
+	}
+
+	rewritten, err := ParseFile(pth)
+	if err != nil {
+		t.Fatalf("Failed to parse the written result, error: %s", err)
+	}
+	if !reflect.DeepEqual(rewritten, run) {
+		t.Fatalf("the written result differs from the parsed one")
+	}
+}
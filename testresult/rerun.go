@@ -0,0 +1,78 @@
+package testresult
+
+// FlakyPropertyName is the name of the property, which marks the test cases failed at first, but passed on a rerun.
+const FlakyPropertyName = "Flaky"
+
+// FailedTestNames returns the full names of the failed test cases.
+func (run TestRun) FailedTestNames() []string {
+	names := []string{}
+	for _, testCase := range run.TestCases() {
+		if testCase.IsFailed() {
+			names = append(names, testCase.FullName)
+		}
+	}
+	return names
+}
+
+// FlakyTestNames returns the full names of the test cases, which failed at first, but passed on a rerun.
+func (run TestRun) FlakyTestNames() []string {
+	names := []string{}
+	for _, testCase := range run.TestCases() {
+		if testCase.IsFlaky() {
+			names = append(names, testCase.FullName)
+		}
+	}
+	return names
+}
+
+// ApplyRerun replaces the results of the test cases, which were rerun, with their results in the rerun.
+// It returns the full names of the test cases, which failed originally, but passed in the rerun (flaky tests).
+func (run *TestRun) ApplyRerun(rerun TestRun) []string {
+	rerunTestCases := map[string]TestCase{}
+	for _, testCase := range rerun.TestCases() {
+		rerunTestCases[testCase.FullName] = testCase
+	}
+
+	flakyTestNames := []string{}
+	for i := range run.TestSuites {
+		flakyTestNames = append(flakyTestNames, run.TestSuites[i].applyRerun(rerunTestCases)...)
+	}
+
+	run.Duration += rerun.Duration
+	if rerun.EndTime != "" {
+		run.EndTime = rerun.EndTime
+	}
+	run.Recount()
+
+	return flakyTestNames
+}
+
+func (suite *TestSuite) applyRerun(rerunTestCases map[string]TestCase) []string {
+	flakyTestNames := []string{}
+
+	for i := range suite.TestSuites {
+		flakyTestNames = append(flakyTestNames, suite.TestSuites[i].applyRerun(rerunTestCases)...)
+	}
+
+	for i, testCase := range suite.TestCases {
+		rerunTestCase, ok := rerunTestCases[testCase.FullName]
+		if !ok {
+			continue
+		}
+
+		if testCase.IsFailed() && rerunTestCase.Result == ResultPassed {
+			rerunTestCase.Properties = append(rerunTestCase.Properties, Property{Name: FlakyPropertyName, Value: "True"})
+			flakyTestNames = append(flakyTestNames, testCase.FullName)
+		}
+
+		suite.TestCases[i] = rerunTestCase
+	}
+
+	return flakyTestNames
+}
+
+// IsFlaky reports whether the test case failed at first, but passed on a rerun.
+func (testCase TestCase) IsFlaky() bool {
+	value, ok := testCase.Property(FlakyPropertyName)
+	return ok && value == "True"
+}
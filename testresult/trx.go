@@ -0,0 +1,195 @@
+package testresult
+
+import (
+	"encoding/xml"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Visual Studio test result (TRX) format, written by the trx logger of dotnet test.
+
+type trxTestRun struct {
+	XMLName xml.Name `xml:"TestRun"`
+
+	ID string `xml:"id,attr"`
+
+	Times struct {
+		Start  string `xml:"start,attr"`
+		Finish string `xml:"finish,attr"`
+	} `xml:"Times"`
+
+	Results         []trxUnitTestResult `xml:"Results>UnitTestResult"`
+	TestDefinitions []trxUnitTest       `xml:"TestDefinitions>UnitTest"`
+}
+
+type trxUnitTestResult struct {
+	TestID    string `xml:"testId,attr"`
+	TestName  string `xml:"testName,attr"`
+	Duration  string `xml:"duration,attr"`
+	StartTime string `xml:"startTime,attr"`
+	EndTime   string `xml:"endTime,attr"`
+	Outcome   string `xml:"outcome,attr"`
+
+	Output struct {
+		StdOut    string `xml:"StdOut"`
+		ErrorInfo *struct {
+			Message    string `xml:"Message"`
+			StackTrace string `xml:"StackTrace"`
+		} `xml:"ErrorInfo"`
+	} `xml:"Output"`
+}
+
+type trxUnitTest struct {
+	ID      string `xml:"id,attr"`
+	Name    string `xml:"name,attr"`
+	Storage string `xml:"storage,attr"`
+
+	Categories []struct {
+		Name string `xml:"TestCategory,attr"`
+	} `xml:"TestCategory>TestCategoryItem"`
+
+	TestMethod struct {
+		CodeBase  string `xml:"codeBase,attr"`
+		ClassName string `xml:"className,attr"`
+		Name      string `xml:"name,attr"`
+	} `xml:"TestMethod"`
+}
+
+// parseTRX converts a TRX result into the NUnit 3 model:
+// an assembly suite for each test assembly, with a fixture suite for each test class.
+func parseTRX(content []byte) (TestRun, error) {
+	var trx trxTestRun
+	if err := xml.Unmarshal(content, &trx); err != nil {
+		return TestRun{}, err
+	}
+
+	definitions := map[string]trxUnitTest{}
+	for _, definition := range trx.TestDefinitions {
+		definitions[definition.ID] = definition
+	}
+
+	run := TestRun{
+		ID:        trx.ID,
+		StartTime: trx.Times.Start,
+		EndTime:   trx.Times.Finish,
+	}
+
+	assemblyIdx := map[string]int{}
+	fixtureIdx := map[string]int{}
+
+	for _, result := range trx.Results {
+		definition := definitions[result.TestID]
+
+		assemblyPth := definition.TestMethod.CodeBase
+		if assemblyPth == "" {
+			assemblyPth = definition.Storage
+		}
+		idx, ok := assemblyIdx[assemblyPth]
+		if !ok {
+			idx = len(run.TestSuites)
+			assemblyIdx[assemblyPth] = idx
+			run.TestSuites = append(run.TestSuites, TestSuite{
+				Type:     "Assembly",
+				Name:     filepath.Base(assemblyPth),
+				FullName: assemblyPth,
+			})
+		}
+		assembly := &run.TestSuites[idx]
+
+		className := definition.TestMethod.ClassName
+		fixtureKey := assemblyPth + "|" + className
+		fIdx, ok := fixtureIdx[fixtureKey]
+		if !ok {
+			fIdx = len(assembly.TestSuites)
+			fixtureIdx[fixtureKey] = fIdx
+			assembly.TestSuites = append(assembly.TestSuites, TestSuite{
+				Type:      "TestFixture",
+				Name:      className[strings.LastIndex(className, ".")+1:],
+				FullName:  className,
+				ClassName: className,
+			})
+		}
+		fixture := &assembly.TestSuites[fIdx]
+
+		testCase := convertTRXTestResult(result, definition)
+		fixture.TestCases = append(fixture.TestCases, testCase)
+		fixture.Duration += testCase.Duration
+		assembly.Duration += testCase.Duration
+		run.Duration += testCase.Duration
+	}
+
+	run.Recount()
+
+	return run, nil
+}
+
+func convertTRXTestResult(result trxUnitTestResult, definition trxUnitTest) TestCase {
+	className := definition.TestMethod.ClassName
+
+	name := result.TestName
+	fullName := name
+	if className != "" && !strings.HasPrefix(name, className+".") {
+		fullName = className + "." + name
+	}
+
+	testCase := TestCase{
+		ID:         result.TestID,
+		Name:       name,
+		FullName:   fullName,
+		MethodName: definition.TestMethod.Name,
+		ClassName:  className,
+		StartTime:  result.StartTime,
+		EndTime:    result.EndTime,
+		Duration:   parseTRXDuration(result.Duration),
+		Output:     CData(result.Output.StdOut),
+	}
+
+	for _, category := range definition.Categories {
+		testCase.Properties = append(testCase.Properties, Property{Name: categoryPropertyName, Value: category.Name})
+	}
+
+	testCase.Result, testCase.Label = convertTRXOutcome(result.Outcome)
+
+	if result.Output.ErrorInfo != nil {
+		switch testCase.Result {
+		case ResultFailed:
+			testCase.Failure = &Failure{Message: result.Output.ErrorInfo.Message, StackTrace: result.Output.ErrorInfo.StackTrace}
+		default:
+			testCase.Reason = &Reason{Message: result.Output.ErrorInfo.Message}
+		}
+	}
+
+	return testCase
+}
+
+func convertTRXOutcome(outcome string) (string, string) {
+	switch outcome {
+	case "Passed", "PassedButRunAborted":
+		return ResultPassed, ""
+	case "Failed":
+		return ResultFailed, ""
+	case "Error", "Timeout", "Aborted", "NotRunnable":
+		return ResultFailed, LabelError
+	case "NotExecuted", "Disconnected":
+		return ResultSkipped, LabelIgnored
+	case "Warning":
+		return ResultWarning, ""
+	default:
+		return ResultInconclusive, ""
+	}
+}
+
+// parseTRXDuration parses a duration like 00:00:01.2345678 to seconds.
+func parseTRXDuration(value string) float64 {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return 0
+	}
+
+	hours, _ := strconv.ParseFloat(parts[0], 64)
+	minutes, _ := strconv.ParseFloat(parts[1], 64)
+	seconds, _ := strconv.ParseFloat(parts[2], 64)
+
+	return hours*3600 + minutes*60 + seconds
+}
@@ -0,0 +1,228 @@
+package testrunner
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-steplib/steps-nunit-runner/testresult"
+	"github.com/bitrise-tools/go-xamarin/analyzers/project"
+)
+
+// TestInput defines what the nunit consoles run: the test projects, or their built test assemblies.
+type TestInput string
+
+const (
+	// TestInputProject passes the test project and its configuration to the console, the console evaluates the project.
+	TestInputProject TestInput = "project"
+	// TestInputAssembly passes the built test assembly of the test project to the console.
+	TestInputAssembly TestInput = "assembly"
+)
+
+// SetTestInput sets what the nunit consoles run.
+// If singleInvocation is true, the test assemblies run by nunit3-console are passed to one console invocation.
+func (runner *Model) SetTestInput(input TestInput, singleInvocation bool) {
+	runner.testInput = input
+	runner.singleInvocation = singleInvocation
+}
+
+// testInputPth returns the console input of the test project (the project or its assembly) and the configuration option.
+func (runner Model) testInputPth(proj project.Model, projectConfig project.ConfigurationPlatformModel) (string, string, error) {
+	if runner.assemblyInputs {
+		return proj.Pth, "", nil
+	}
+	if runner.testInput != TestInputAssembly {
+		return proj.Pth, projectConfig.Configuration, nil
+	}
+
+	pth, err := assemblyPth(proj, projectConfig)
+	if err != nil {
+		return "", "", err
+	}
+	return pth, "", nil
+}
+
+// assemblyPth returns the path of the assembly built by the project, in the output directory of the project configuration.
+func assemblyPth(proj project.Model, projectConfig project.ConfigurationPlatformModel) (string, error) {
+	if projectConfig.OutputDir == "" {
+		return "", fmt.Errorf("project (%s) does not have output directory for the project config (%s|%s)", proj.Name, projectConfig.Configuration, projectConfig.Platform)
+	}
+
+	assemblyName := proj.AssemblyName
+	if assemblyName == "" {
+		assemblyName = proj.Name
+	}
+
+	ext := ".dll"
+	if proj.OutputType == "exe" {
+		ext = ".exe"
+	}
+
+	return filepath.Join(projectConfig.OutputDir, assemblyName+ext), nil
+}
+
+func isAssemblyPth(pth string) bool {
+	ext := strings.ToLower(filepath.Ext(pth))
+	return ext == ".dll" || ext == ".exe"
+}
+
+// checkInputsExist checks that the test projects or test assemblies exist, before passing them to a test runner.
+func checkInputsExist(pths ...string) error {
+	for _, pth := range pths {
+		if exist, err := pathutil.IsPathExists(pth); err != nil {
+			return fmt.Errorf("Failed to check if path (%s) exist, error: %s", pth, err)
+		} else if !exist {
+			return fmt.Errorf("test input not exist at: %s, the test projects have to be built before running their tests", pth)
+		}
+	}
+	return nil
+}
+
+// combinesTestAssemblies reports whether the test assemblies run by nunit3-console are passed to one console invocation.
+func (runner Model) combinesTestAssemblies() bool {
+	return runner.singleInvocation && (runner.testInput == TestInputAssembly || runner.assemblyInputs)
+}
+
+// combinedNunitConsoleCommand runs the test assemblies of more test projects in one nunit3-console invocation,
+// and splits the test result (or the listed tests) into the result files of the test projects, by assembly.
+type combinedNunitConsoleCommand struct {
+	*nunitConsoleCommand
+
+	parts  []*nunitConsoleCommand
+	tmpDir string
+}
+
+// combineNunitConsoleCommands replaces the nunit3-console commands of the test assemblies with a single combined command,
+// in the place of the first one. The commands are combined only if every one of them, or none of them have a test list.
+// The .nunit project files are not combined, their results can not be split by assembly.
+func (runner Model) combineNunitConsoleCommands(testProjects []project.Model, commands []TestCommand) ([]project.Model, []TestCommand, error) {
+	partIdxs := []int{}
+	withTestList := 0
+	for i, command := range commands {
+		if nunitConsole, ok := command.(*nunitConsoleCommand); ok && isAssemblyPth(nunitConsole.inputPths[0]) {
+			partIdxs = append(partIdxs, i)
+			if nunitConsole.testListPth != "" {
+				withTestList++
+			}
+		}
+	}
+	if len(partIdxs) < 2 || (withTestList > 0 && withTestList < len(partIdxs)) {
+		return testProjects, commands, nil
+	}
+
+	tmpDir, err := pathutil.NormalizedOSTempDirPath("nunit-combined")
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to create tmp dir, error: %s", err)
+	}
+
+	combined := &combinedNunitConsoleCommand{tmpDir: tmpDir}
+	combinedProject := testProjects[partIdxs[0]]
+	names := []string{}
+
+	isPart := map[int]bool{}
+	for _, i := range partIdxs {
+		isPart[i] = true
+		part := commands[i].(*nunitConsoleCommand)
+		combined.parts = append(combined.parts, part)
+		names = append(names, testProjects[i].Name)
+	}
+
+	first := *combined.parts[0]
+	combined.nunitConsoleCommand = &first
+	combined.inputPths = []string{}
+	for _, part := range combined.parts {
+		combined.inputPths = append(combined.inputPths, part.inputPths...)
+	}
+	if first.explorePth != "" {
+		combined.explorePth = filepath.Join(tmpDir, "explore.xml")
+	}
+	if first.resultLogPth != "" {
+		combined.resultLogPth = filepath.Join(tmpDir, "TestResult.xml")
+	}
+	if first.testListPth != "" {
+		combined.testListPth = filepath.Join(tmpDir, "testlist.txt")
+	}
+
+	combinedProject.Name = strings.Join(names, ", ")
+
+	projects := []project.Model{}
+	combinedCommands := []TestCommand{}
+	for i := range commands {
+		if i == partIdxs[0] {
+			projects = append(projects, combinedProject)
+			combinedCommands = append(combinedCommands, combined)
+		} else if !isPart[i] {
+			projects = append(projects, testProjects[i])
+			combinedCommands = append(combinedCommands, commands[i])
+		}
+	}
+
+	return projects, combinedCommands, nil
+}
+
+// Run ...
+func (combined *combinedNunitConsoleCommand) Run() error {
+	if combined.testListPth != "" {
+		testList := ""
+		for _, part := range combined.parts {
+			content, err := ioutil.ReadFile(part.testListPth)
+			if err != nil {
+				return fmt.Errorf("Failed to read test list (%s), error: %s", part.testListPth, err)
+			}
+			testList += strings.TrimSuffix(string(content), "\n") + "\n"
+		}
+
+		if err := fileutil.WriteStringToFile(combined.testListPth, testList); err != nil {
+			return fmt.Errorf("Failed to write test list, error: %s", err)
+		}
+	}
+
+	runErr := combined.nunitConsoleCommand.Run()
+
+	if err := combined.split(); err != nil {
+		if runErr != nil {
+			return runErr
+		}
+		return err
+	}
+
+	return runErr
+}
+
+// split writes the result (or the listed tests) of each test assembly to the result file of its test project.
+func (combined combinedNunitConsoleCommand) split() error {
+	pth := combined.resultLogPth
+	if combined.explorePth != "" {
+		pth = combined.explorePth
+	}
+	if pth == "" {
+		return nil
+	}
+
+	if exist, err := pathutil.IsPathExists(pth); err != nil {
+		return fmt.Errorf("Failed to check if path (%s) exist, error: %s", pth, err)
+	} else if !exist {
+		return fmt.Errorf("test result not exist at: %s", pth)
+	}
+
+	run, err := testresult.ParseFile(pth)
+	if err != nil {
+		return err
+	}
+
+	for _, part := range combined.parts {
+		partPth := part.resultLogPth
+		if part.explorePth != "" {
+			partPth = part.explorePth
+		}
+
+		if err := run.SelectAssembly(part.inputPths[0]).WriteFile(partPth); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package testrunner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bitrise-tools/go-xamarin/analyzers/project"
+	"github.com/bitrise-tools/go-xamarin/analyzers/solution"
+	"github.com/bitrise-tools/go-xamarin/constants"
+)
+
+const nunitProjectExt = ".nunit"
+
+// NewWithTestAssemblies creates a runner for built test assemblies, without a solution:
+// the test assemblies (or a .nunit project file) are run by nunit3-console, without analyzing and building a solution.
+// The patterns are glob patterns of the test assemblies, ** matches any number of directories.
+// The runner handles each test assembly as a test project, named after the assembly file.
+func NewWithTestAssemblies(patterns []string) (Model, error) {
+	pths := []string{}
+	added := map[string]bool{}
+	for _, pattern := range patterns {
+		matches, err := globAssemblies(pattern)
+		if err != nil {
+			return Model{}, err
+		}
+
+		for _, match := range matches {
+			if !added[match] {
+				pths = append(pths, match)
+				added[match] = true
+			}
+		}
+	}
+	if len(pths) == 0 {
+		return Model{}, fmt.Errorf("no test assembly found, patterns: %v", patterns)
+	}
+
+	testAssemblies := solution.Model{
+		Name:       "test assemblies",
+		ConfigMap:  map[string]string{},
+		ProjectMap: map[string]project.Model{},
+	}
+	projectIDs := []string{}
+	nameCounts := map[string]int{}
+
+	for _, pth := range pths {
+		name := strings.TrimSuffix(filepath.Base(pth), filepath.Ext(pth))
+		nameCounts[name]++
+		if nameCounts[name] > 1 {
+			// the result files are named after the test projects
+			name = fmt.Sprintf("%s_%d", name, nameCounts[name])
+		}
+
+		testAssemblies.ProjectMap[pth] = project.Model{
+			ID:            pth,
+			Name:          name,
+			Pth:           pth,
+			TestFramework: constants.TestFrameworkNunitTest,
+			ConfigMap:     map[string]string{},
+			Configs:       map[string]project.ConfigurationPlatformModel{},
+		}
+		projectIDs = append(projectIDs, pth)
+	}
+
+	return Model{
+		solution:           testAssemblies,
+		solutionProjectIDs: projectIDs,
+		assemblyInputs:     true,
+		projectOrder:       ProjectOrderSolution,
+		concurrency:        1,
+		sdkStyleProjectIDs: map[string]bool{},
+		nunit2ProjectIDs:   map[string]bool{},
+		testRunnerMode:     TestRunnerNunitConsole,
+		dotnetTestLogger:   DotnetTestLoggerTRX,
+		testInput:          TestInputAssembly,
+	}, nil
+}
+
+// IsTestAssemblies reports whether the runner runs built test assemblies, instead of the test projects of a solution.
+func (runner Model) IsTestAssemblies() bool {
+	return runner.assemblyInputs
+}
+
+// globAssemblies returns the absolute paths of the files matching the pattern, in lexical order.
+func globAssemblies(pattern string) ([]string, error) {
+	absPattern, err := filepath.Abs(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to expand path (%s), error: %s", pattern, err)
+	}
+
+	if !strings.Contains(absPattern, "**") {
+		matches, err := filepath.Glob(absPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern (%s), error: %s", pattern, err)
+		}
+		sort.Strings(matches)
+		return matches, nil
+	}
+
+	// walk from the longest directory prefix without wildcards
+	root := absPattern[:strings.IndexAny(absPattern, "*?[")]
+	root = root[:strings.LastIndex(root, string(filepath.Separator))+1]
+
+	re, err := regexp.Compile(globPatternToRegexp(absPattern))
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern (%s), error: %s", pattern, err)
+	}
+
+	matches := []string{}
+	if err := filepath.Walk(root, func(pth string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !info.IsDir() && re.MatchString(pth) {
+			matches = append(matches, pth)
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("Failed to search for pattern (%s), error: %s", pattern, err)
+	}
+
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// globPatternToRegexp converts a glob pattern to a regular expression:
+// ** matches any number of directories, * and ? match within a path component.
+func globPatternToRegexp(pattern string) string {
+	separator := regexp.QuoteMeta(string(filepath.Separator))
+
+	expr := "^"
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**"+string(filepath.Separator)):
+			expr += "(.*" + separator + ")?"
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr += ".*"
+			i++
+		case pattern[i] == '*':
+			expr += "[^" + separator + "]*"
+		case pattern[i] == '?':
+			expr += "[^" + separator + "]"
+		default:
+			expr += regexp.QuoteMeta(string(pattern[i]))
+		}
+	}
+	return expr + "$"
+}
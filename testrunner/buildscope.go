@@ -0,0 +1,210 @@
+package testrunner
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/bitrise-tools/go-xamarin/analyzers/project"
+	"github.com/bitrise-tools/go-xamarin/builder"
+	"github.com/bitrise-tools/go-xamarin/constants"
+	"github.com/bitrise-tools/go-xamarin/tools"
+	"github.com/bitrise-tools/go-xamarin/tools/buildtools"
+	"github.com/bitrise-tools/go-xamarin/tools/buildtools/msbuild"
+	"github.com/bitrise-tools/go-xamarin/tools/buildtools/xbuild"
+	"github.com/bitrise-tools/go-xamarin/utility"
+)
+
+// BuildScope defines what is built before running the tests.
+type BuildScope string
+
+const (
+	// BuildScopeSolution builds the whole solution.
+	BuildScopeSolution BuildScope = "solution"
+	// BuildScopeTestProjects builds the test projects to run and the projects they refer to (directly or indirectly) only.
+	BuildScopeTestProjects BuildScope = "test_projects"
+)
+
+// SetBuildScope sets what is built before running the tests.
+func (runner *Model) SetBuildScope(scope BuildScope) {
+	runner.buildScope = scope
+}
+
+// Build builds the solution, or the test projects and the projects they refer to, depending on the build scope.
+func (runner Model) Build(configuration, platform string, callback builder.BuildCommandCallback) ([]string, error) {
+	if runner.buildScope != BuildScopeTestProjects {
+		return nil, runner.BuildSolution(configuration, platform, callback)
+	}
+	return runner.BuildTestProjects(configuration, platform, callback)
+}
+
+// BuildTestProjects builds the selected nunit test projects and the projects they refer to, in reference order.
+// The projects are built one by one, without building their project references again.
+func (runner Model) BuildTestProjects(configuration, platform string, callback builder.BuildCommandCallback) ([]string, error) {
+	if runner.assemblyInputs {
+		return nil, fmt.Errorf("No test project to build, the test assemblies are run")
+	}
+	if err := validateSolutionConfig(runner.solution, configuration, platform); err != nil {
+		return nil, err
+	}
+
+	// the skipped test projects are reported by the test run
+	testProjects, _ := runner.selectNunitTestProjects(configuration, platform)
+	if len(testProjects) == 0 {
+		return nil, fmt.Errorf("No project to build found")
+	}
+
+	buildCommands, warnings, err := runner.buildTestProjectsCommands(configuration, platform, testProjects)
+	if err != nil {
+		return warnings, fmt.Errorf("Failed to create build command, error: %s", err)
+	}
+
+	for _, buildCommand := range buildCommands {
+		// Callback to notify the caller about next running command
+		if callback != nil {
+			callback(runner.solution.Name, buildCommand.projectName, constants.SDKUnknown, constants.TestFrameworkUnknown, buildCommand.PrintableCommand(), false)
+		}
+
+		if err := buildCommand.Run(); err != nil {
+			return warnings, err
+		}
+	}
+
+	return warnings, nil
+}
+
+// projectBuildCommand is the build command of a project of the solution.
+type projectBuildCommand struct {
+	tools.Runnable
+
+	projectName string
+}
+
+// buildCommands returns the build commands of the build scope: the solution build command,
+// or the build commands of the test projects and the projects they refer to.
+func (runner Model) buildCommands(configuration, platform string, testProjects []project.Model) ([]tools.Runnable, []string, error) {
+	if runner.buildScope != BuildScopeTestProjects {
+		buildCommand, err := runner.buildSolutionCommand(configuration, platform)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []tools.Runnable{buildCommand}, nil, nil
+	}
+
+	projectBuildCommands, warnings, err := runner.buildTestProjectsCommands(configuration, platform, testProjects)
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	buildCommands := []tools.Runnable{}
+	for _, buildCommand := range projectBuildCommands {
+		buildCommands = append(buildCommands, buildCommand)
+	}
+	return buildCommands, warnings, nil
+}
+
+// buildTestProjectsCommands returns the build commands of the test projects and the projects they refer to,
+// the referred projects are built before the projects referring to them.
+func (runner Model) buildTestProjectsCommands(configuration, platform string, testProjects []project.Model) ([]projectBuildCommand, []string, error) {
+	projects, warnings := runner.referenceClosure(testProjects)
+
+	buildCommands := []projectBuildCommand{}
+	for _, proj := range projects {
+		buildCommand, warns, err := runner.buildProjectCommand(configuration, platform, proj)
+		warnings = append(warnings, warns...)
+		if err != nil {
+			return nil, warnings, err
+		}
+
+		buildCommands = append(buildCommands, projectBuildCommand{Runnable: buildCommand, projectName: proj.Name})
+	}
+
+	return buildCommands, warnings, nil
+}
+
+// referenceClosure returns the projects and the projects they refer to (directly or indirectly),
+// every project after the projects it refers to.
+func (runner Model) referenceClosure(projects []project.Model) ([]project.Model, []string) {
+	closure := []project.Model{}
+	warnings := []string{}
+	visited := map[string]bool{}
+
+	var visit func(proj project.Model)
+	visit = func(proj project.Model) {
+		if visited[proj.ID] {
+			return
+		}
+		visited[proj.ID] = true
+
+		for _, referredProjectID := range proj.ReferredProjectIDs {
+			referredProj, ok := runner.solution.ProjectMap[referredProjectID]
+			if !ok {
+				warnings = append(warnings, fmt.Sprintf("project reference exist with project id: %s, but project not found in solution", referredProjectID))
+				continue
+			}
+			visit(referredProj)
+		}
+
+		closure = append(closure, proj)
+	}
+
+	for _, proj := range projects {
+		visit(proj)
+	}
+
+	return closure, warnings
+}
+
+// buildProjectCommand returns the build command of the project, with the project config mapped to the solution config.
+func (runner Model) buildProjectCommand(configuration, platform string, proj project.Model) (tools.Runnable, []string, error) {
+	warnings := []string{}
+
+	solutionConfig := utility.ToConfig(configuration, platform)
+
+	projectConfigKey, ok := proj.ConfigMap[solutionConfig]
+	if !ok {
+		warnings = append(warnings, fmt.Sprintf("project (%s) do not have config for solution config (%s), building with the solution config", proj.Name, solutionConfig))
+		projectConfigKey = solutionConfig
+	}
+
+	split := strings.Split(projectConfigKey, "|")
+	if len(split) != 2 {
+		return nil, warnings, fmt.Errorf("invalid project config (%s) of project (%s)", projectConfigKey, proj.Name)
+	}
+
+	var command *xbuild.Model
+	var err error
+
+	if runner.buildTool == buildtools.Msbuild {
+		command, err = msbuild.New(runner.solution.Pth, proj.Pth)
+	} else {
+		command, err = xbuild.New(runner.solution.Pth, proj.Pth)
+	}
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	if runner.buildToolPth != "" {
+		command.BuildTool = runner.buildToolPth
+	}
+
+	command.SetTarget("Build")
+	command.SetConfiguration(split[0])
+	command.SetPlatform(split[1])
+
+	// the projects of the closure are built in reference order, the project references are already built,
+	// except for SDK-style projects: they refer to the projects by path, which are not tracked as references
+	if sdkStyle, err := isSDKStyleProject(proj.Pth); err != nil || !sdkStyle {
+		command.SetCustomOptions("/p:BuildProjectReferences=false")
+	}
+
+	return command, warnings, nil
+}
+
+func isSDKStyleProject(pth string) (bool, error) {
+	content, err := ioutil.ReadFile(pth)
+	if err != nil {
+		return false, err
+	}
+	return sdkStyleProjectPattern.Match(content), nil
+}
@@ -0,0 +1,193 @@
+package testrunner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bitrise-tools/go-xamarin/analyzers/project"
+	"github.com/bitrise-tools/go-xamarin/constants"
+	"github.com/bitrise-tools/go-xamarin/tools"
+	"github.com/bitrise-tools/go-xamarin/tools/buildtools"
+	"github.com/bitrise-tools/go-xamarin/tools/buildtools/msbuild"
+	"github.com/bitrise-tools/go-xamarin/tools/buildtools/xbuild"
+	"github.com/bitrise-tools/go-xamarin/utility"
+)
+
+func (runner Model) buildSolutionCommand(configuration, platform string) (tools.Runnable, error) {
+	var command *xbuild.Model
+	var err error
+
+	if runner.buildTool == buildtools.Msbuild {
+		command, err = msbuild.New(runner.solution.Pth, "")
+	} else {
+		command, err = xbuild.New(runner.solution.Pth, "")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if runner.buildToolPth != "" {
+		command.BuildTool = runner.buildToolPth
+	}
+
+	command.SetTarget("Build")
+	command.SetConfiguration(configuration)
+	command.SetPlatform(platform)
+
+	return command, nil
+}
+
+// runsWithDotnetTest reports whether the test project runs with dotnet test, instead of nunit3-console.
+// NUnitLite test projects are their own test runners.
+func (runner Model) runsWithDotnetTest(proj project.Model) bool {
+	if proj.TestFramework == constants.TestFrameworkNunitLiteTest {
+		return false
+	}
+
+	switch runner.testRunnerMode {
+	case TestRunnerDotnetTest:
+		return true
+	case TestRunnerNunitConsole:
+		return false
+	default:
+		return runner.sdkStyleProjectIDs[proj.ID]
+	}
+}
+
+func (runner Model) buildTestProjectCommand(configuration, platform string, proj project.Model) (TestCommand, []string, error) {
+	if proj.TestFramework == constants.TestFrameworkNunitLiteTest {
+		return runner.buildNunitLiteTestProjectCommand(configuration, platform, proj)
+	}
+
+	if runner.runsWithDotnetTest(proj) {
+		// SDK-style projects do not have configurations in the project file, the mapped solution configuration is used
+		projectConfiguration := configuration
+		if projectConfigKey, ok := proj.ConfigMap[utility.ToConfig(configuration, platform)]; ok {
+			projectConfiguration = strings.Split(projectConfigKey, "|")[0]
+		}
+
+		command := newDotnetTestCommand(runner.dotnetPth, proj.Pth, projectConfiguration, runner.dotnetTestLogger)
+		command.where = runner.testFilter.Expression()
+		command.SetCustomOptions(runner.customOptions.DotnetTest...)
+
+		return command, nil, nil
+	}
+
+	if runner.nunit2ProjectIDs[proj.ID] {
+		return runner.buildNunit2TestProjectCommand(configuration, platform, proj)
+	}
+
+	nunitConsolePth, err := runner.nunitConsolePath()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	command, warnings, err := runner.buildNunitTestProjectCommand(configuration, platform, proj, nunitConsolePth)
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	command.where = runner.testFilter.Expression()
+	command.SetCustomOptions(runner.customOptions.NunitConsole...)
+
+	return command, warnings, nil
+}
+
+func (runner Model) buildNunit2TestProjectCommand(configuration, platform string, proj project.Model) (TestCommand, []string, error) {
+	nunitConsolePth, err := runner.nunit2ConsolePath()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	projectConfig, warnings := runner.projectConfiguration(configuration, platform, proj)
+
+	command, err := newNunit2ConsoleCommand(nunitConsolePth)
+	if err != nil {
+		return nil, warnings, err
+	}
+	if runner.monoPth != "" {
+		command.monoPth = runner.monoPth
+	}
+
+	inputPth, config, err := runner.testInputPth(proj, projectConfig)
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	command.inputPth = inputPth
+	command.config = config
+	command.includeCategories = runner.testFilter.IncludeCategories
+	command.excludeCategories = runner.testFilter.ExcludeCategories
+	command.SetCustomOptions(runner.customOptions.Nunit2Console...)
+
+	return command, warnings, nil
+}
+
+func (runner Model) buildNunitLiteTestProjectCommand(configuration, platform string, proj project.Model) (TestCommand, []string, error) {
+	if reason := nunitLiteRunnerSkipReason(proj); reason != "" {
+		return nil, nil, fmt.Errorf("project (%s) %s", proj.Name, reason)
+	}
+
+	projectConfig, warnings := runner.projectConfiguration(configuration, platform, proj)
+
+	exePth, err := assemblyPth(proj, projectConfig)
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	command := newNunitLiteCommand(exePth)
+	if runner.monoPth != "" {
+		command.monoPth = runner.monoPth
+	}
+
+	command.where = runner.testFilter.Expression()
+	command.SetCustomOptions(runner.customOptions.NunitLite...)
+
+	return command, warnings, nil
+}
+
+// projectConfiguration returns the project configuration mapped to the solution configuration.
+// The test assemblies do not have configurations, they are already built.
+func (runner Model) projectConfiguration(configuration, platform string, proj project.Model) (project.ConfigurationPlatformModel, []string) {
+	warnings := []string{}
+	if runner.assemblyInputs {
+		return project.ConfigurationPlatformModel{}, warnings
+	}
+
+	solutionConfig := utility.ToConfig(configuration, platform)
+
+	projectConfigKey, ok := proj.ConfigMap[solutionConfig]
+	if !ok {
+		warnings = append(warnings, fmt.Sprintf("project (%s) do not have config for solution config (%s), skipping...", proj.Name, solutionConfig))
+	}
+
+	projectConfig, ok := proj.Configs[projectConfigKey]
+	if !ok {
+		warnings = append(warnings, fmt.Sprintf("project (%s) contains mapping for solution config (%s), but does not have project configuration", proj.Name, solutionConfig))
+	}
+
+	return projectConfig, warnings
+}
+
+func (runner Model) buildNunitTestProjectCommand(configuration, platform string, proj project.Model, nunitConsolePth string) (*nunitConsoleCommand, []string, error) {
+	projectConfig, warnings := runner.projectConfiguration(configuration, platform, proj)
+
+	command, err := newNunitConsoleCommand(nunitConsolePth)
+	if err != nil {
+		return nil, warnings, err
+	}
+	if runner.monoPth != "" {
+		command.monoPth = runner.monoPth
+	}
+
+	inputPth, config, err := runner.testInputPth(proj, projectConfig)
+	if err != nil {
+		return nil, warnings, err
+	}
+
+	command.inputPths = []string{inputPth}
+	command.config = config
+
+	return command, warnings, nil
+}
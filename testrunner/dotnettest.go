@@ -0,0 +1,177 @@
+package testrunner
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/command"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-steplib/steps-nunit-runner/filter"
+	"github.com/bitrise-steplib/steps-nunit-runner/outcome"
+)
+
+var (
+	sdkStyleProjectPattern       = regexp.MustCompile(`(?i)<Project\s+[^>]*Sdk\s*=`)
+	nunitPackageReferencePattern = regexp.MustCompile(`(?i)<PackageReference\s+Include\s*=\s*"NUnit"`)
+)
+
+// isSDKStyleNunitProject reports whether the project file is an SDK-style project (<Project Sdk="...">)
+// referring to the NUnit package.
+func isSDKStyleNunitProject(pth string) (bool, error) {
+	content, err := ioutil.ReadFile(pth)
+	if err != nil {
+		return false, err
+	}
+	return sdkStyleProjectPattern.Match(content) && nunitPackageReferencePattern.Match(content), nil
+}
+
+// dotnetTestCommand runs the tests of an SDK-style test project with dotnet test and the NUnit test adapter.
+type dotnetTestCommand struct {
+	dotnetPth string
+
+	projectPth    string
+	configuration string
+
+	logger       DotnetTestLogger
+	resultLogPth string
+	where        string
+	testNames    []string
+	explorePth   string
+
+	customOptions []string
+
+	dir    string
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func newDotnetTestCommand(dotnetPth, projectPth, configuration string, logger DotnetTestLogger) *dotnetTestCommand {
+	if dotnetPth == "" {
+		dotnetPth = "dotnet"
+	}
+
+	return &dotnetTestCommand{
+		dotnetPth:     dotnetPth,
+		projectPth:    projectPth,
+		configuration: configuration,
+		logger:        logger,
+		stdout:        os.Stdout,
+		stderr:        os.Stderr,
+	}
+}
+
+// SetCustomOptions ...
+func (dotnetTest *dotnetTestCommand) SetCustomOptions(options ...string) {
+	dotnetTest.customOptions = options
+}
+
+// SetResultLogPth ...
+func (dotnetTest *dotnetTestCommand) SetResultLogPth(pth string) {
+	dotnetTest.resultLogPth = pth
+}
+
+// SetTestList ...
+func (dotnetTest *dotnetTestCommand) SetTestList(pth string, testNames []string) {
+	dotnetTest.testNames = testNames
+}
+
+// SetExplorePth ...
+func (dotnetTest *dotnetTestCommand) SetExplorePth(pth string) {
+	dotnetTest.explorePth = pth
+}
+
+func (dotnetTest *dotnetTestCommand) setOutput(dir string, out io.Writer) {
+	dotnetTest.dir = dir
+	dotnetTest.stdout = out
+	dotnetTest.stderr = out
+}
+
+// whereExpression combines the test filter and the selected test names into the NUnit.Where run setting of the NUnit test adapter.
+func (dotnetTest dotnetTestCommand) whereExpression() string {
+	if len(dotnetTest.testNames) == 0 {
+		return dotnetTest.where
+	}
+
+	terms := []string{}
+	for _, name := range dotnetTest.testNames {
+		terms = append(terms, "test == "+filter.Quote(name))
+	}
+	testNamesExpression := strings.Join(terms, " or ")
+
+	if dotnetTest.where == "" {
+		return testNamesExpression
+	}
+	return fmt.Sprintf("(%s) and (%s)", dotnetTest.where, testNamesExpression)
+}
+
+func (dotnetTest dotnetTestCommand) commandSlice() []string {
+	cmdSlice := []string{dotnetTest.dotnetPth, "test", dotnetTest.projectPth}
+
+	if dotnetTest.configuration != "" {
+		cmdSlice = append(cmdSlice, "--configuration", dotnetTest.configuration)
+	}
+
+	if dotnetTest.resultLogPth != "" {
+		switch dotnetTest.logger {
+		case DotnetTestLoggerNUnit:
+			cmdSlice = append(cmdSlice, "--logger", "nunit;LogFilePath="+dotnetTest.resultLogPth)
+		default:
+			cmdSlice = append(cmdSlice,
+				"--results-directory", filepath.Dir(dotnetTest.resultLogPth),
+				"--logger", "trx;LogFileName="+filepath.Base(dotnetTest.resultLogPth))
+		}
+	}
+
+	cmdSlice = append(cmdSlice, dotnetTest.customOptions...)
+
+	if where := dotnetTest.whereExpression(); where != "" {
+		cmdSlice = append(cmdSlice, "--", "NUnit.Where="+where)
+	}
+
+	return cmdSlice
+}
+
+// PrintableCommand ...
+func (dotnetTest dotnetTestCommand) PrintableCommand() string {
+	return command.PrintableCommandArgs(true, dotnetTest.commandSlice())
+}
+
+// Run ...
+func (dotnetTest dotnetTestCommand) Run() error {
+	if dotnetTest.explorePth != "" {
+		return fmt.Errorf("listing the tests of %s is not supported with dotnet test", dotnetTest.projectPth)
+	}
+
+	cmd, err := command.NewFromSlice(dotnetTest.commandSlice())
+	if err != nil {
+		return err
+	}
+
+	if dotnetTest.dir != "" {
+		cmd.SetDir(dotnetTest.dir)
+	}
+	cmd.SetStdout(dotnetTest.stdout)
+	cmd.SetStderr(dotnetTest.stderr)
+
+	if err := removeTestResult(dotnetTest.resultLogPth); err != nil {
+		return err
+	}
+
+	if err := cmd.Run(); err != nil {
+		// dotnet test exits with 1 both if the tests failed and if the test project failed to build,
+		// the test result is written only if the tests run
+		if dotnetTest.resultLogPth != "" {
+			if exist, existErr := pathutil.IsPathExists(dotnetTest.resultLogPth); existErr == nil && !exist {
+				return outcome.BuildError{Err: fmt.Errorf("Failed to build %s, error: %s", dotnetTest.projectPth, err)}
+			}
+		}
+		return err
+	}
+
+	return nil
+}
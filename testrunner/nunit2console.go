@@ -0,0 +1,131 @@
+package testrunner
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/command"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-steplib/steps-nunit-runner/outcome"
+	"github.com/bitrise-tools/go-xamarin/constants"
+)
+
+// nunit2ConsoleCommand runs the tests of an NUnit 2 test project with nunit-console.exe,
+// which has a different syntax than nunit3-console.exe: /xml: for the result, /runlist: for the selected tests.
+type nunit2ConsoleCommand struct {
+	monoPth         string
+	nunitConsolePth string
+
+	// the test project, or the test assembly
+	inputPth string
+	config   string
+
+	resultLogPth      string
+	testListPth       string
+	includeCategories []string
+	excludeCategories []string
+	explorePth        string
+
+	customOptions []string
+
+	dir    string
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func newNunit2ConsoleCommand(nunitConsolePth string) (*nunit2ConsoleCommand, error) {
+	absNunitConsolePth, err := pathutil.AbsPath(nunitConsolePth)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to expand path (%s), error: %s", nunitConsolePth, err)
+	}
+
+	return &nunit2ConsoleCommand{
+		monoPth:         constants.MonoPath,
+		nunitConsolePth: absNunitConsolePth,
+		stdout:          os.Stdout,
+		stderr:          os.Stderr,
+	}, nil
+}
+
+// SetCustomOptions ...
+func (nunitConsole *nunit2ConsoleCommand) SetCustomOptions(options ...string) {
+	nunitConsole.customOptions = options
+}
+
+// SetResultLogPth ...
+func (nunitConsole *nunit2ConsoleCommand) SetResultLogPth(pth string) {
+	nunitConsole.resultLogPth = pth
+}
+
+// SetTestList ...
+func (nunitConsole *nunit2ConsoleCommand) SetTestList(pth string, testNames []string) {
+	nunitConsole.testListPth = pth
+}
+
+// SetExplorePth ...
+func (nunitConsole *nunit2ConsoleCommand) SetExplorePth(pth string) {
+	nunitConsole.explorePth = pth
+}
+
+func (nunitConsole *nunit2ConsoleCommand) setOutput(dir string, out io.Writer) {
+	nunitConsole.dir = dir
+	nunitConsole.stdout = out
+	nunitConsole.stderr = out
+}
+
+func (nunitConsole nunit2ConsoleCommand) commandSlice() []string {
+	cmdSlice := []string{nunitConsole.monoPth, nunitConsole.nunitConsolePth, nunitConsole.inputPth}
+
+	if nunitConsole.config != "" {
+		cmdSlice = append(cmdSlice, "/config:"+nunitConsole.config)
+	}
+	if nunitConsole.resultLogPth != "" {
+		cmdSlice = append(cmdSlice, "/xml:"+nunitConsole.resultLogPth)
+	}
+	if nunitConsole.testListPth != "" {
+		// the test list file has a test name per line, /run: would split the parameterized test names at their commas
+		cmdSlice = append(cmdSlice, "/runlist:"+nunitConsole.testListPth)
+	}
+	if len(nunitConsole.includeCategories) > 0 {
+		cmdSlice = append(cmdSlice, "/include:"+strings.Join(nunitConsole.includeCategories, ","))
+	}
+	if len(nunitConsole.excludeCategories) > 0 {
+		cmdSlice = append(cmdSlice, "/exclude:"+strings.Join(nunitConsole.excludeCategories, ","))
+	}
+
+	return append(cmdSlice, nunitConsole.customOptions...)
+}
+
+// PrintableCommand ...
+func (nunitConsole nunit2ConsoleCommand) PrintableCommand() string {
+	return command.PrintableCommandArgs(true, nunitConsole.commandSlice())
+}
+
+// Run ...
+func (nunitConsole nunit2ConsoleCommand) Run() error {
+	if nunitConsole.explorePth != "" {
+		return fmt.Errorf("listing the tests of %s is not supported with nunit-console.exe (NUnit 2)", nunitConsole.inputPth)
+	}
+	if err := checkInputsExist(nunitConsole.inputPth); err != nil {
+		return err
+	}
+
+	cmd, err := command.NewFromSlice(nunitConsole.commandSlice())
+	if err != nil {
+		return err
+	}
+
+	if nunitConsole.dir != "" {
+		cmd.SetDir(nunitConsole.dir)
+	}
+	cmd.SetStdout(nunitConsole.stdout)
+	cmd.SetStderr(nunitConsole.stderr)
+
+	if err := removeTestResult(nunitConsole.resultLogPth); err != nil {
+		return err
+	}
+
+	return testCommandError(cmd.Run(), outcome.TestRunnerNunit2Console, nunitConsole.resultLogPth)
+}
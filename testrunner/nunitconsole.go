@@ -0,0 +1,126 @@
+package testrunner
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bitrise-io/go-utils/command"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-steplib/steps-nunit-runner/outcome"
+	"github.com/bitrise-tools/go-xamarin/constants"
+)
+
+// nunitConsoleCommand is an nunit console command, like the one of go-xamarin's nunit package,
+// but its working directory and outputs can be set, to run more test projects at the same time.
+type nunitConsoleCommand struct {
+	monoPth         string
+	nunitConsolePth string
+
+	// the test projects, or the test assemblies
+	inputPths []string
+	config    string
+
+	resultLogPth string
+	where        string
+	testListPth  string
+	explorePth   string
+
+	customOptions []string
+
+	dir    string
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func newNunitConsoleCommand(nunitConsolePth string) (*nunitConsoleCommand, error) {
+	absNunitConsolePth, err := pathutil.AbsPath(nunitConsolePth)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to expand path (%s), error: %s", nunitConsolePth, err)
+	}
+
+	return &nunitConsoleCommand{
+		monoPth:         constants.MonoPath,
+		nunitConsolePth: absNunitConsolePth,
+		stdout:          os.Stdout,
+		stderr:          os.Stderr,
+	}, nil
+}
+
+// SetCustomOptions ...
+func (nunitConsole *nunitConsoleCommand) SetCustomOptions(options ...string) {
+	nunitConsole.customOptions = options
+}
+
+// SetResultLogPth ...
+func (nunitConsole *nunitConsoleCommand) SetResultLogPth(pth string) {
+	nunitConsole.resultLogPth = pth
+}
+
+// SetTestList ...
+func (nunitConsole *nunitConsoleCommand) SetTestList(pth string, testNames []string) {
+	nunitConsole.testListPth = pth
+}
+
+// SetExplorePth ...
+func (nunitConsole *nunitConsoleCommand) SetExplorePth(pth string) {
+	nunitConsole.explorePth = pth
+}
+
+func (nunitConsole *nunitConsoleCommand) setOutput(dir string, out io.Writer) {
+	nunitConsole.dir = dir
+	nunitConsole.stdout = out
+	nunitConsole.stderr = out
+}
+
+func (nunitConsole nunitConsoleCommand) commandSlice() []string {
+	cmdSlice := []string{nunitConsole.monoPth, nunitConsole.nunitConsolePth}
+
+	cmdSlice = append(cmdSlice, nunitConsole.inputPths...)
+	if nunitConsole.config != "" {
+		cmdSlice = append(cmdSlice, fmt.Sprintf("/config:%s", nunitConsole.config))
+	}
+
+	if nunitConsole.explorePth != "" {
+		cmdSlice = append(cmdSlice, "--explore="+nunitConsole.explorePth)
+	} else if nunitConsole.resultLogPth != "" {
+		cmdSlice = append(cmdSlice, "--result", nunitConsole.resultLogPth)
+	}
+	if nunitConsole.where != "" {
+		cmdSlice = append(cmdSlice, "--where", nunitConsole.where)
+	}
+	if nunitConsole.testListPth != "" {
+		cmdSlice = append(cmdSlice, "--testlist", nunitConsole.testListPth)
+	}
+
+	return append(cmdSlice, nunitConsole.customOptions...)
+}
+
+// PrintableCommand ...
+func (nunitConsole nunitConsoleCommand) PrintableCommand() string {
+	return command.PrintableCommandArgs(true, nunitConsole.commandSlice())
+}
+
+// Run ...
+func (nunitConsole nunitConsoleCommand) Run() error {
+	if err := checkInputsExist(nunitConsole.inputPths...); err != nil {
+		return err
+	}
+
+	cmd, err := command.NewFromSlice(nunitConsole.commandSlice())
+	if err != nil {
+		return err
+	}
+
+	if nunitConsole.dir != "" {
+		cmd.SetDir(nunitConsole.dir)
+	}
+	cmd.SetStdout(nunitConsole.stdout)
+	cmd.SetStderr(nunitConsole.stderr)
+
+	if err := removeTestResult(nunitConsole.resultLogPth); err != nil {
+		return err
+	}
+
+	return testCommandError(cmd.Run(), outcome.TestRunnerNunitConsole, nunitConsole.resultLogPth)
+}
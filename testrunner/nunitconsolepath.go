@@ -0,0 +1,219 @@
+package testrunner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-tools/go-xamarin/tools/nunit"
+)
+
+const (
+	nunit3Console = "nunit3-console.exe"
+	nunit2Console = "nunit-console.exe"
+)
+
+// NunitConsole is an nunit3-console.exe (or an NUnit 2 nunit-console.exe), with its version (if known) and the place it was found at.
+type NunitConsole struct {
+	Pth     string
+	Version string
+	Source  string
+}
+
+// consolePackage is the NuGet package distributing a console runner.
+type consolePackage struct {
+	consoleName string
+	packageName string
+	systemPath  func() (string, error)
+}
+
+var (
+	nunit3ConsolePackage = consolePackage{
+		consoleName: nunit3Console,
+		packageName: "NUnit.ConsoleRunner",
+		systemPath:  nunit.SystemNunit3ConsolePath,
+	}
+	nunit2ConsolePackage = consolePackage{
+		consoleName: nunit2Console,
+		packageName: "NUnit.Runners",
+		systemPath:  systemNunit2ConsolePath,
+	}
+)
+
+// SetNunitConsolePath sets the path of the nunit3-console.exe to use, it is discovered by FindNunitConsole if not set.
+func (runner *Model) SetNunitConsolePath(pth string) {
+	runner.nunitConsolePth = pth
+}
+
+// SetNunit2ConsolePath sets the path of the NUnit 2 nunit-console.exe to use, it is discovered by FindNunit2Console if not set.
+func (runner *Model) SetNunit2ConsolePath(pth string) {
+	runner.nunit2ConsolePth = pth
+}
+
+// FindNunitConsole returns the nunit console at the given path (nunit3-console.exe or the directory containing it).
+// If the path is empty, it discovers the console in the NUnit.ConsoleRunner packages of the solution's packages directory
+// and of the global NuGet cache, choosing the highest version, and falls back to the NUNIT_PATH environment.
+func (runner Model) FindNunitConsole(pth string) (NunitConsole, error) {
+	return runner.findConsole(pth, nunit3ConsolePackage)
+}
+
+// FindNunit2Console returns the NUnit 2 console at the given path (nunit-console.exe or the directory containing it).
+// If the path is empty, it discovers the console in the NUnit.Runners packages, the same way as FindNunitConsole.
+func (runner Model) FindNunit2Console(pth string) (NunitConsole, error) {
+	return runner.findConsole(pth, nunit2ConsolePackage)
+}
+
+func (runner Model) findConsole(pth string, pkg consolePackage) (NunitConsole, error) {
+	if pth != "" {
+		if filepath.Ext(pth) != ".exe" {
+			pth = filepath.Join(pth, pkg.consoleName)
+		}
+
+		if exist, err := pathutil.IsPathExists(pth); err != nil {
+			return NunitConsole{}, fmt.Errorf("Failed to check if nunit console exist at (%s), error: %s", pth, err)
+		} else if !exist {
+			return NunitConsole{}, fmt.Errorf("nunit console not exist at: %s", pth)
+		}
+
+		return NunitConsole{Pth: pth, Source: "input"}, nil
+	}
+
+	candidates := []NunitConsole{}
+
+	packagesDir := filepath.Join(filepath.Dir(runner.solution.Pth), "packages")
+	if matches, err := filepath.Glob(filepath.Join(packagesDir, pkg.packageName+".*", "tools", pkg.consoleName)); err == nil {
+		for _, match := range matches {
+			packageDir := filepath.Base(filepath.Dir(filepath.Dir(match)))
+			candidates = append(candidates, NunitConsole{
+				Pth:     match,
+				Version: strings.TrimPrefix(packageDir, pkg.packageName+"."),
+				Source:  "solution packages",
+			})
+		}
+	}
+
+	if nugetPackagesDir := nugetPackagesDir(); nugetPackagesDir != "" {
+		if matches, err := filepath.Glob(filepath.Join(nugetPackagesDir, strings.ToLower(pkg.packageName), "*", "tools", pkg.consoleName)); err == nil {
+			for _, match := range matches {
+				candidates = append(candidates, NunitConsole{
+					Pth:     match,
+					Version: filepath.Base(filepath.Dir(filepath.Dir(match))),
+					Source:  "NuGet cache",
+				})
+			}
+		}
+	}
+
+	if len(candidates) > 0 {
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return compareVersions(candidates[i].Version, candidates[j].Version) > 0
+		})
+		return candidates[0], nil
+	}
+
+	systemPth, err := pkg.systemPath()
+	if err != nil {
+		return NunitConsole{}, fmt.Errorf("%s not found in the packages directory (%s) and in the NuGet cache, and %s", pkg.consoleName, packagesDir, err)
+	}
+	return NunitConsole{Pth: systemPth, Source: "NUNIT_PATH"}, nil
+}
+
+// systemNunit2ConsolePath returns the nunit-console.exe of the NUNIT_PATH directory.
+func systemNunit2ConsolePath() (string, error) {
+	nunitDir := os.Getenv("NUNIT_PATH")
+	if nunitDir == "" {
+		return "", fmt.Errorf("NUNIT_PATH environment is not set, failed to determine nunit console path")
+	}
+
+	nunitConsolePth := filepath.Join(nunitDir, nunit2Console)
+	if exist, err := pathutil.IsPathExists(nunitConsolePth); err != nil {
+		return "", fmt.Errorf("Failed to check if nunit console exist at (%s), error: %s", nunitConsolePth, err)
+	} else if !exist {
+		return "", fmt.Errorf("nunit console not exist at: %s", nunitConsolePth)
+	}
+
+	return nunitConsolePth, nil
+}
+
+func (runner Model) nunitConsolePath() (string, error) {
+	if runner.nunitConsolePth != "" {
+		return runner.nunitConsolePth, nil
+	}
+
+	console, err := runner.FindNunitConsole("")
+	if err != nil {
+		return "", err
+	}
+	return console.Pth, nil
+}
+
+func (runner Model) nunit2ConsolePath() (string, error) {
+	if runner.nunit2ConsolePth != "" {
+		return runner.nunit2ConsolePth, nil
+	}
+
+	console, err := runner.FindNunit2Console("")
+	if err != nil {
+		return "", err
+	}
+	return console.Pth, nil
+}
+
+func nugetPackagesDir() string {
+	if dir := os.Getenv("NUGET_PACKAGES"); dir != "" {
+		return dir
+	}
+	if home := os.Getenv("HOME"); home != "" {
+		return filepath.Join(home, ".nuget", "packages")
+	}
+	return ""
+}
+
+// compareVersions compares package versions, like 3.10.0 and 3.9.0-beta1:
+// numeric components are compared as numbers, and a release is higher than its pre-releases.
+func compareVersions(a, b string) int {
+	aVersion, aPrerelease := splitPrerelease(a)
+	bVersion, bPrerelease := splitPrerelease(b)
+
+	aParts := strings.Split(aVersion, ".")
+	bParts := strings.Split(bVersion, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		aPart, bPart := 0, 0
+		if i < len(aParts) {
+			aPart, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			bPart, _ = strconv.Atoi(bParts[i])
+		}
+		if aPart != bPart {
+			if aPart > bPart {
+				return 1
+			}
+			return -1
+		}
+	}
+
+	switch {
+	case aPrerelease == bPrerelease:
+		return 0
+	case aPrerelease == "":
+		return 1
+	case bPrerelease == "":
+		return -1
+	case aPrerelease > bPrerelease:
+		return 1
+	default:
+		return -1
+	}
+}
+
+func splitPrerelease(version string) (string, string) {
+	if idx := strings.Index(version, "-"); idx >= 0 {
+		return version[:idx], version[idx+1:]
+	}
+	return version, ""
+}
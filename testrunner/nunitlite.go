@@ -0,0 +1,129 @@
+package testrunner
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bitrise-io/go-utils/command"
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-steplib/steps-nunit-runner/outcome"
+	"github.com/bitrise-tools/go-xamarin/analyzers/project"
+	"github.com/bitrise-tools/go-xamarin/constants"
+)
+
+// nunitLiteRunnerSkipReason returns why the NUnitLite test project can not run as a console executable under mono,
+// or an empty string if it can. Only desktop console executables are NUnitLite runners:
+// the Touch.Unit (iOS, tvOS) and Android test apps also refer to MonoTouch.NUnitLite, but they run on a device.
+func nunitLiteRunnerSkipReason(proj project.Model) string {
+	switch proj.SDK {
+	case constants.SDKIOS, constants.SDKTvOS, constants.SDKAndroid:
+		return fmt.Sprintf("is an NUnitLite test app (sdk: %s), which runs on a device or simulator", proj.SDK)
+	}
+	if proj.OutputType != "exe" {
+		return fmt.Sprintf("is an NUnitLite test project, but does not build a console executable (output type: %s)", proj.OutputType)
+	}
+	return ""
+}
+
+// nunitLiteCommand runs the tests of an NUnitLite test project: the built console executable is the test runner.
+type nunitLiteCommand struct {
+	monoPth string
+	exePth  string
+
+	resultLogPth string
+	where        string
+	testListPth  string
+	explorePth   string
+
+	customOptions []string
+
+	dir    string
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func newNunitLiteCommand(exePth string) *nunitLiteCommand {
+	return &nunitLiteCommand{
+		monoPth: constants.MonoPath,
+		exePth:  exePth,
+		stdout:  os.Stdout,
+		stderr:  os.Stderr,
+	}
+}
+
+// SetCustomOptions ...
+func (nunitLite *nunitLiteCommand) SetCustomOptions(options ...string) {
+	nunitLite.customOptions = options
+}
+
+// SetResultLogPth ...
+func (nunitLite *nunitLiteCommand) SetResultLogPth(pth string) {
+	nunitLite.resultLogPth = pth
+}
+
+// SetTestList ...
+func (nunitLite *nunitLiteCommand) SetTestList(pth string, testNames []string) {
+	nunitLite.testListPth = pth
+}
+
+// SetExplorePth ...
+func (nunitLite *nunitLiteCommand) SetExplorePth(pth string) {
+	nunitLite.explorePth = pth
+}
+
+func (nunitLite *nunitLiteCommand) setOutput(dir string, out io.Writer) {
+	nunitLite.dir = dir
+	nunitLite.stdout = out
+	nunitLite.stderr = out
+}
+
+func (nunitLite nunitLiteCommand) commandSlice() []string {
+	cmdSlice := []string{nunitLite.monoPth, nunitLite.exePth}
+
+	if nunitLite.explorePth != "" {
+		cmdSlice = append(cmdSlice, "--explore="+nunitLite.explorePth)
+	} else if nunitLite.resultLogPth != "" {
+		cmdSlice = append(cmdSlice, "--result="+nunitLite.resultLogPth)
+	}
+	if nunitLite.where != "" {
+		cmdSlice = append(cmdSlice, "--where="+nunitLite.where)
+	}
+	if nunitLite.testListPth != "" {
+		cmdSlice = append(cmdSlice, "--testlist="+nunitLite.testListPth)
+	}
+
+	return append(cmdSlice, nunitLite.customOptions...)
+}
+
+// PrintableCommand ...
+func (nunitLite nunitLiteCommand) PrintableCommand() string {
+	return command.PrintableCommandArgs(true, nunitLite.commandSlice())
+}
+
+// Run ...
+func (nunitLite nunitLiteCommand) Run() error {
+	// the executable is built by the build step, it is checked only before running it
+	if exist, err := pathutil.IsPathExists(nunitLite.exePth); err != nil {
+		return fmt.Errorf("Failed to check if path (%s) exist, error: %s", nunitLite.exePth, err)
+	} else if !exist {
+		return fmt.Errorf("NUnitLite test executable not exist at: %s, the test project has to be built before running its tests", nunitLite.exePth)
+	}
+
+	cmd, err := command.NewFromSlice(nunitLite.commandSlice())
+	if err != nil {
+		return err
+	}
+
+	if nunitLite.dir != "" {
+		cmd.SetDir(nunitLite.dir)
+	}
+	cmd.SetStdout(nunitLite.stdout)
+	cmd.SetStderr(nunitLite.stderr)
+
+	if err := removeTestResult(nunitLite.resultLogPth); err != nil {
+		return err
+	}
+
+	return testCommandError(cmd.Run(), outcome.TestRunnerNunitConsole, nunitLite.resultLogPth)
+}
@@ -0,0 +1,60 @@
+package testrunner
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/pathutil"
+)
+
+var (
+	// <Reference Include="nunit.framework, Version=2.6.4.14350, Culture=neutral, PublicKeyToken=96d09a1eb7f44a77">
+	nunitReferenceVersionPattern = regexp.MustCompile(`(?i)<Reference\s+Include="nunit\.framework,\s*Version=([0-9.]+)`)
+	// <HintPath>..\packages\NUnit.2.6.4\lib\nunit.framework.dll</HintPath>
+	nunitHintPathVersionPattern = regexp.MustCompile(`(?i)<HintPath>[^<]*[\\/]NUnit\.([0-9][0-9.]*[0-9])[\\/][^<]*nunit\.framework\.dll</HintPath>`)
+	// <PackageReference Include="NUnit" Version="3.12.0" />
+	nunitPackageReferenceVersionPattern = regexp.MustCompile(`(?i)<PackageReference\s+Include="NUnit"\s+Version="([0-9.]+)`)
+	// <package id="NUnit" version="2.6.4" targetFramework="net45" />
+	nunitPackagesConfigVersionPattern = regexp.MustCompile(`(?i)<package\s+id="NUnit"\s+version="([0-9.]+)"`)
+)
+
+// nunitFrameworkVersion returns the version of the NUnit framework the project refers to,
+// based on the nunit.framework reference, the NUnit package reference or the packages.config of the project.
+// It returns an empty version if the version is not known.
+func nunitFrameworkVersion(pth string) (string, error) {
+	content, err := ioutil.ReadFile(pth)
+	if err != nil {
+		return "", fmt.Errorf("Failed to read project (%s), error: %s", pth, err)
+	}
+
+	for _, pattern := range []*regexp.Regexp{nunitReferenceVersionPattern, nunitHintPathVersionPattern, nunitPackageReferenceVersionPattern} {
+		if matches := pattern.FindSubmatch(content); len(matches) == 2 {
+			return string(matches[1]), nil
+		}
+	}
+
+	packagesConfigPth := filepath.Join(filepath.Dir(pth), "packages.config")
+	if exist, err := pathutil.IsPathExists(packagesConfigPth); err != nil {
+		return "", fmt.Errorf("Failed to check if path (%s) exist, error: %s", packagesConfigPth, err)
+	} else if !exist {
+		return "", nil
+	}
+
+	packagesConfig, err := ioutil.ReadFile(packagesConfigPth)
+	if err != nil {
+		return "", fmt.Errorf("Failed to read packages.config (%s), error: %s", packagesConfigPth, err)
+	}
+
+	if matches := nunitPackagesConfigVersionPattern.FindSubmatch(packagesConfig); len(matches) == 2 {
+		return string(matches[1]), nil
+	}
+	return "", nil
+}
+
+// isNunit2Version reports whether the NUnit framework version is a 2.x version.
+func isNunit2Version(version string) bool {
+	return strings.HasPrefix(version, "2.")
+}
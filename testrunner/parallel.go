@@ -0,0 +1,118 @@
+package testrunner
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-tools/go-xamarin/analyzers/project"
+	"github.com/bitrise-tools/go-xamarin/builder"
+	"github.com/bitrise-tools/go-xamarin/constants"
+)
+
+// SetConcurrency sets the number of test projects to run at the same time.
+func (runner *Model) SetConcurrency(concurrency int) {
+	runner.concurrency = concurrency
+}
+
+type projectRunResult struct {
+	idx    int
+	output bytes.Buffer
+	err    error
+}
+
+// runNunitTestProjectsInParallel runs at most runner.concurrency test projects at the same time,
+// a test project starts after the test projects it refers to finished.
+// Each project runs in its own working directory, with a buffered output, which is printed when the project finished.
+func (runner Model) runNunitTestProjectsInParallel(testProjects []project.Model, commands []TestCommand, callback builder.BuildCommandCallback) error {
+	workDir, err := pathutil.NormalizedOSTempDirPath("nunit-workers")
+	if err != nil {
+		return fmt.Errorf("Failed to create tmp dir, error: %s", err)
+	}
+
+	idxByID := map[string]int{}
+	for i, proj := range testProjects {
+		idxByID[proj.ID] = i
+	}
+
+	isReady := func(i int, finished map[int]bool) bool {
+		for _, referredID := range testProjects[i].ReferredProjectIDs {
+			if j, ok := idxByID[referredID]; ok && j != i && !finished[j] {
+				return false
+			}
+		}
+		return true
+	}
+
+	pending := []int{}
+	for i := range testProjects {
+		pending = append(pending, i)
+	}
+	finished := map[int]bool{}
+	running := 0
+	// buffered, so that a worker never blocks on reporting its result
+	results := make(chan *projectRunResult, len(testProjects))
+
+	var runErr, outputErr error
+
+	for len(pending) > 0 || running > 0 {
+		for running < runner.concurrency && len(pending) > 0 {
+			next := -1
+			for k, i := range pending {
+				if isReady(i, finished) {
+					next = k
+					break
+				}
+			}
+			if next < 0 {
+				if running > 0 {
+					break
+				}
+				// reference cycle between the pending projects
+				next = 0
+			}
+
+			i := pending[next]
+			pending = append(pending[:next], pending[next+1:]...)
+			running++
+
+			go func(i int) {
+				result := &projectRunResult{idx: i}
+
+				dir := filepath.Join(workDir, fmt.Sprintf("%d_%s", i, testProjects[i].Name))
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					result.err = fmt.Errorf("Failed to create working directory, error: %s", err)
+				} else {
+					commands[i].setOutput(dir, &result.output)
+					result.err = commands[i].Run()
+				}
+
+				results <- result
+			}(i)
+		}
+
+		result := <-results
+		running--
+		finished[result.idx] = true
+
+		// Callback to notify the caller about the finished command, followed by its output
+		if callback != nil {
+			callback(runner.solution.Name, testProjects[result.idx].Name, constants.SDKUnknown, constants.TestFrameworkNunitTest, commands[result.idx].PrintableCommand(), false)
+		}
+		// the running projects are waited for, even if their output can not be printed
+		if _, err := os.Stdout.Write(result.output.Bytes()); err != nil && outputErr == nil {
+			outputErr = fmt.Errorf("Failed to write output of %s, error: %s", testProjects[result.idx].Name, err)
+		}
+
+		if result.err != nil {
+			runErr = moreSevereError(runErr, result.err)
+		}
+	}
+
+	if runErr != nil {
+		return runErr
+	}
+	return outputErr
+}
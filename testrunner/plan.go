@@ -0,0 +1,161 @@
+package testrunner
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-tools/go-xamarin/builder"
+	"github.com/bitrise-tools/go-xamarin/utility"
+)
+
+// Plan describes the commands of a run, without executing them.
+type Plan struct {
+	Solution       string `json:"solution"`
+	SolutionConfig string `json:"solution_config"`
+
+	BuildCommands []string `json:"build_commands,omitempty"`
+
+	Projects        []PlannedProject `json:"projects"`
+	SkippedProjects []SkippedProject `json:"skipped_projects"`
+
+	Warnings []string `json:"warnings"`
+}
+
+// PlannedProject is a test project to run, with its mapped project config and its command.
+type PlannedProject struct {
+	Name          string `json:"name"`
+	Pth           string `json:"path"`
+	Configuration string `json:"configuration"`
+	Platform      string `json:"platform"`
+	OutputDir     string `json:"output_dir"`
+	Command       string `json:"command"`
+}
+
+// Plan creates the build command (if build is true) and the test commands of the selected nunit test projects,
+// the same way as BuildAndRunAllNunitTestProjects and RunAllNunitTestProjects, but does not execute them.
+func (runner Model) Plan(configuration, platform string, build bool, prepareCallback builder.PrepareCommandCallback) (Plan, error) {
+	solutionConfig := utility.ToConfig(configuration, platform)
+
+	plan := Plan{
+		Solution:        runner.solution.Pth,
+		SolutionConfig:  solutionConfig,
+		Projects:        []PlannedProject{},
+		SkippedProjects: []SkippedProject{},
+		Warnings:        []string{},
+	}
+
+	if err := runner.validateConfig(configuration, platform); err != nil {
+		return plan, err
+	}
+
+	testProjects, skippedProjects := runner.selectNunitTestProjects(configuration, platform)
+	plan.SkippedProjects = append(plan.SkippedProjects, skippedProjects...)
+
+	if build {
+		buildCommands, warnings, err := runner.buildCommands(configuration, platform, testProjects)
+		plan.Warnings = append(plan.Warnings, warnings...)
+		if err != nil {
+			return plan, fmt.Errorf("Failed to create build command, error: %s", err)
+		}
+		for _, buildCommand := range buildCommands {
+			plan.BuildCommands = append(plan.BuildCommands, buildCommand.PrintableCommand())
+		}
+	}
+
+	// the console placeholders are used if the consoles are not found
+	var consoleErr, nunit2ConsoleErr error
+	if runner.UsesNunitConsole(configuration, platform) {
+		if _, consoleErr = runner.nunitConsolePath(); consoleErr != nil {
+			plan.Warnings = append(plan.Warnings, consoleErr.Error())
+			runner.nunitConsolePth = nunit3Console
+		}
+	}
+	if runner.UsesNunit2Console(configuration, platform) {
+		if _, nunit2ConsoleErr = runner.nunit2ConsolePath(); nunit2ConsoleErr != nil {
+			plan.Warnings = append(plan.Warnings, nunit2ConsoleErr.Error())
+			runner.nunit2ConsolePth = nunit2Console
+		}
+	}
+
+	commands, warnings, err := runner.prepareNunitTestProjectCommands(configuration, platform, testProjects, prepareCallback)
+	plan.Warnings = append(plan.Warnings, warnings...)
+	if err != nil {
+		return plan, err
+	}
+
+	// the projects of a combined command are listed with the combined command
+	combinedCommands := map[TestCommand]string{}
+	if runner.combinesTestAssemblies() {
+		combinedProjects, combinedProjectCommands, err := runner.combineNunitConsoleCommands(testProjects, commands)
+		if err != nil {
+			return plan, err
+		}
+
+		for i, command := range combinedProjectCommands {
+			combined, ok := command.(*combinedNunitConsoleCommand)
+			if !ok {
+				continue
+			}
+			if consoleErr != nil {
+				combined.nunitConsolePth = nunit3Console
+			}
+			for _, part := range combined.parts {
+				combinedCommands[part] = combined.PrintableCommand()
+			}
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("The test assemblies of %s run in one nunit console invocation", combinedProjects[i].Name))
+		}
+	}
+
+	for i, proj := range testProjects {
+		switch command := commands[i].(type) {
+		case *nunitConsoleCommand:
+			if consoleErr != nil {
+				command.nunitConsolePth = nunit3Console
+			}
+		case *nunit2ConsoleCommand:
+			if nunit2ConsoleErr != nil {
+				command.nunitConsolePth = nunit2Console
+			}
+		}
+
+		projectConfigKey := proj.ConfigMap[solutionConfig]
+		projectConfig, ok := proj.Configs[projectConfigKey]
+		if !ok {
+			// SDK-style projects do not define their configs, only the mapped config is known
+			if split := strings.Split(projectConfigKey, "|"); len(split) == 2 {
+				projectConfig.Configuration, projectConfig.Platform = split[0], split[1]
+			}
+		}
+
+		command := commands[i].PrintableCommand()
+		if combinedCommand, ok := combinedCommands[commands[i]]; ok {
+			command = combinedCommand
+		}
+
+		plan.Projects = append(plan.Projects, PlannedProject{
+			Name:          proj.Name,
+			Pth:           proj.Pth,
+			Configuration: projectConfig.Configuration,
+			Platform:      projectConfig.Platform,
+			OutputDir:     projectConfig.OutputDir,
+			Command:       command,
+		})
+	}
+
+	return plan, nil
+}
+
+// WriteFile writes the plan as JSON.
+func (plan Plan) WriteFile(pth string) error {
+	content, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to serialize plan, error: %s", err)
+	}
+
+	if err := fileutil.WriteBytesToFile(pth, append(content, '\n')); err != nil {
+		return fmt.Errorf("Failed to write plan (%s), error: %s", pth, err)
+	}
+	return nil
+}
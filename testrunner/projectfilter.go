@@ -0,0 +1,79 @@
+package testrunner
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bitrise-tools/go-xamarin/analyzers/project"
+)
+
+// ProjectFilter selects the test projects to run.
+// A pattern is a glob (like Integration*) or a regular expression between slashes (like /\.Integration\.Tests$/),
+// and it is matched against the project name and the project path, relative to the solution directory.
+type ProjectFilter struct {
+	// Include selects the projects matching any of the patterns, every project is selected if empty
+	Include []string
+	// Exclude deselects the projects matching any of the patterns
+	Exclude []string
+}
+
+// Validate ...
+func (filter ProjectFilter) Validate() error {
+	for _, pattern := range append(append([]string{}, filter.Include...), filter.Exclude...) {
+		if _, err := matchProjectPattern(pattern, "", ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// match reports whether the project is selected, and the reason if not.
+func (filter ProjectFilter) match(proj project.Model, solutionDir string) (bool, string) {
+	relPth, err := filepath.Rel(solutionDir, proj.Pth)
+	if err != nil {
+		relPth = proj.Pth
+	}
+
+	if len(filter.Include) > 0 {
+		included := false
+		for _, pattern := range filter.Include {
+			if match, err := matchProjectPattern(pattern, proj.Name, relPth); err == nil && match {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false, "does not match the included projects"
+		}
+	}
+
+	for _, pattern := range filter.Exclude {
+		if match, err := matchProjectPattern(pattern, proj.Name, relPth); err == nil && match {
+			return false, fmt.Sprintf("matches the excluded pattern (%s)", pattern)
+		}
+	}
+
+	return true, ""
+}
+
+func matchProjectPattern(pattern, name, pth string) (bool, error) {
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return false, fmt.Errorf("invalid project pattern (%s), error: %s", pattern, err)
+		}
+		return re.MatchString(name) || re.MatchString(filepath.ToSlash(pth)), nil
+	}
+
+	nameMatch, err := filepath.Match(pattern, name)
+	if err != nil {
+		return false, fmt.Errorf("invalid project pattern (%s), error: %s", pattern, err)
+	}
+	if nameMatch {
+		return true, nil
+	}
+
+	return filepath.Match(filepath.FromSlash(pattern), pth)
+}
@@ -0,0 +1,91 @@
+package testrunner
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/bitrise-tools/go-xamarin/analyzers/project"
+	"github.com/bitrise-tools/go-xamarin/constants"
+	"github.com/bitrise-tools/go-xamarin/utility"
+)
+
+// SkippedProject is a test project, which is not run, with the reason.
+type SkippedProject struct {
+	Name   string `json:"name"`
+	Pth    string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+func (runner Model) buildableNunitTestProjects(configuration, platform string) ([]project.Model, []string) {
+	testProjects, skippedProjects := runner.selectNunitTestProjects(configuration, platform)
+
+	warnings := []string{}
+	for _, skipped := range skippedProjects {
+		warnings = append(warnings, fmt.Sprintf("Project (%s) %s, skipping...", skipped.Name, skipped.Reason))
+	}
+
+	return testProjects, warnings
+}
+
+// TestProjectNames returns the names of the nunit test projects to run, and the warnings about the skipped test projects.
+func (runner Model) TestProjectNames(configuration, platform string) ([]string, []string, error) {
+	if err := runner.validateConfig(configuration, platform); err != nil {
+		return nil, nil, err
+	}
+
+	testProjects, warnings := runner.buildableNunitTestProjects(configuration, platform)
+
+	projectNames := []string{}
+	for _, proj := range testProjects {
+		projectNames = append(projectNames, proj.Name)
+	}
+	return projectNames, warnings, nil
+}
+
+// selectedTestProjects returns the nunit test projects to run, without the skipped ones.
+func (runner Model) selectedTestProjects(configuration, platform string) []project.Model {
+	testProjects, _ := runner.selectNunitTestProjects(configuration, platform)
+	return testProjects
+}
+
+func (runner Model) selectNunitTestProjects(configuration, platform string) ([]project.Model, []SkippedProject) {
+	testProjects := []project.Model{}
+	skippedProjects := []SkippedProject{}
+
+	solutionConfig := utility.ToConfig(configuration, platform)
+
+	for _, proj := range runner.orderedProjects() {
+		// Check if is nunit test project
+		if proj.TestFramework != constants.TestFrameworkNunitTest && proj.TestFramework != constants.TestFrameworkNunitLiteTest && !runner.sdkStyleProjectIDs[proj.ID] {
+			continue
+		}
+
+		// NUnitLite test projects run as console executables
+		if proj.TestFramework == constants.TestFrameworkNunitLiteTest {
+			if reason := nunitLiteRunnerSkipReason(proj); reason != "" {
+				skippedProjects = append(skippedProjects, SkippedProject{Name: proj.Name, Pth: proj.Pth, Reason: reason})
+				continue
+			}
+		}
+
+		// Check if contains config mapping, the test assemblies do not have configs
+		_, ok := proj.ConfigMap[solutionConfig]
+		if !ok && !runner.assemblyInputs {
+			skippedProjects = append(skippedProjects, SkippedProject{
+				Name:   proj.Name,
+				Pth:    proj.Pth,
+				Reason: fmt.Sprintf("do not have config for solution config (%s)", solutionConfig),
+			})
+			continue
+		}
+
+		if selected, reason := runner.projectFilter.match(proj, filepath.Dir(runner.solution.Pth)); !selected {
+			skippedProjects = append(skippedProjects, SkippedProject{Name: proj.Name, Pth: proj.Pth, Reason: reason})
+			continue
+		}
+
+		testProjects = append(testProjects, proj)
+	}
+
+	return testProjects, skippedProjects
+}
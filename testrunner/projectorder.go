@@ -0,0 +1,138 @@
+package testrunner
+
+import (
+	"bufio"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/fileutil"
+	"github.com/bitrise-tools/go-xamarin/analyzers/project"
+)
+
+const solutionProjectPattern = `Project\("{[^"]*}"\) = "[^"]*", "[^"]*", "{(?P<project_id>[^"]*)}"`
+
+// ProjectOrder defines the order of the test projects.
+type ProjectOrder string
+
+const (
+	// ProjectOrderSolution runs the projects in the order of the solution file.
+	ProjectOrderSolution ProjectOrder = "solution"
+	// ProjectOrderDependency runs the projects after the projects they refer to, otherwise in the order of the solution file.
+	ProjectOrderDependency ProjectOrder = "dependency"
+)
+
+// SetProjectOrder sets the order of the test projects,
+// the projects matching the priority patterns (see ProjectFilter) run first, in the order of the patterns.
+func (runner *Model) SetProjectOrder(order ProjectOrder, priority []string) {
+	runner.projectOrder = order
+	runner.projectPriority = priority
+}
+
+// solutionProjectIDs returns the project IDs of the solution, in the order of the solution file.
+func solutionProjectIDs(solutionPth string) ([]string, error) {
+	content, err := fileutil.ReadStringFromFile(solutionPth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read solution (%s), error: %s", solutionPth, err)
+	}
+
+	re := regexp.MustCompile(solutionProjectPattern)
+
+	projectIDs := []string{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		if matches := re.FindStringSubmatch(strings.TrimSpace(scanner.Text())); len(matches) == 2 {
+			projectIDs = append(projectIDs, strings.ToUpper(matches[1]))
+		}
+	}
+	return projectIDs, scanner.Err()
+}
+
+// orderedProjects returns the projects of the solution in the order set by SetProjectOrder.
+func (runner Model) orderedProjects() []project.Model {
+	projects := []project.Model{}
+	added := map[string]bool{}
+	for _, projectID := range runner.solutionProjectIDs {
+		if proj, ok := runner.solution.ProjectMap[projectID]; ok && !added[projectID] {
+			projects = append(projects, proj)
+			added[projectID] = true
+		}
+	}
+
+	// projects not found in the solution file, should not happen
+	rest := []project.Model{}
+	for projectID, proj := range runner.solution.ProjectMap {
+		if !added[projectID] {
+			rest = append(rest, proj)
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool { return rest[i].Pth < rest[j].Pth })
+	projects = append(projects, rest...)
+
+	if runner.projectOrder == ProjectOrderDependency {
+		projects = dependencyOrder(projects)
+	}
+
+	return prioritized(projects, runner.projectPriority, filepath.Dir(runner.solution.Pth))
+}
+
+// dependencyOrder moves the projects after the projects they refer to, keeping the original order where possible.
+// The references of a reference cycle are ignored.
+func dependencyOrder(projects []project.Model) []project.Model {
+	projectByID := map[string]project.Model{}
+	for _, proj := range projects {
+		projectByID[proj.ID] = proj
+	}
+
+	ordered := []project.Model{}
+	visited := map[string]bool{}
+
+	var visit func(proj project.Model)
+	visit = func(proj project.Model) {
+		if visited[proj.ID] {
+			return
+		}
+		visited[proj.ID] = true
+
+		for _, referredID := range proj.ReferredProjectIDs {
+			if referred, ok := projectByID[referredID]; ok {
+				visit(referred)
+			}
+		}
+
+		ordered = append(ordered, proj)
+	}
+
+	for _, proj := range projects {
+		visit(proj)
+	}
+
+	return ordered
+}
+
+// prioritized moves the projects matching the priority patterns to the front, in the order of the patterns.
+func prioritized(projects []project.Model, priority []string, solutionDir string) []project.Model {
+	if len(priority) == 0 {
+		return projects
+	}
+
+	rank := func(proj project.Model) int {
+		relPth, err := filepath.Rel(solutionDir, proj.Pth)
+		if err != nil {
+			relPth = proj.Pth
+		}
+
+		for i, pattern := range priority {
+			if match, err := matchProjectPattern(pattern, proj.Name, relPth); err == nil && match {
+				return i
+			}
+		}
+		return len(priority)
+	}
+
+	ordered := append([]project.Model{}, projects...)
+	sort.SliceStable(ordered, func(i, j int) bool { return rank(ordered[i]) < rank(ordered[j]) })
+	return ordered
+}
@@ -0,0 +1,178 @@
+package testrunner
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-steplib/steps-nunit-runner/filter"
+	"github.com/bitrise-steplib/steps-nunit-runner/outcome"
+	"github.com/bitrise-steplib/steps-nunit-runner/testresult"
+	"github.com/bitrise-tools/go-xamarin/constants"
+	"github.com/bitrise-tools/go-xamarin/tools"
+)
+
+// TestCommand is the command running the tests of a test project, with nunit3-console or with dotnet test.
+// The prepare callback of the runner receives it as a tools.Editable.
+type TestCommand interface {
+	tools.Runnable
+
+	// SetResultLogPth sets the path of the test result file.
+	SetResultLogPth(pth string)
+	// SetTestList selects the tests to run: nunit3-console uses the test list file, dotnet test the test names.
+	SetTestList(pth string, testNames []string)
+	// SetExplorePth lists the tests into the given file, instead of running them.
+	SetExplorePth(pth string)
+
+	setOutput(dir string, out io.Writer)
+}
+
+// TestRunnerMode defines which test projects run with dotnet test.
+type TestRunnerMode string
+
+const (
+	// TestRunnerAuto runs the SDK-style test projects with dotnet test, the rest of the test projects with nunit3-console.
+	TestRunnerAuto TestRunnerMode = "auto"
+	// TestRunnerNunitConsole runs every test project with nunit3-console.
+	TestRunnerNunitConsole TestRunnerMode = "nunit3-console"
+	// TestRunnerDotnetTest runs every test project with dotnet test.
+	TestRunnerDotnetTest TestRunnerMode = "dotnet-test"
+)
+
+// DotnetTestLogger is the logger of dotnet test, writing the test results.
+type DotnetTestLogger string
+
+const (
+	// DotnetTestLoggerTRX is the built-in Visual Studio test result (TRX) logger.
+	DotnetTestLoggerTRX DotnetTestLogger = "trx"
+	// DotnetTestLoggerNUnit is the NUnit 3 result logger of the NunitXml.TestLogger package.
+	DotnetTestLoggerNUnit DotnetTestLogger = "nunit"
+)
+
+// SetTestRunner sets which test projects run with dotnet test, and the logger of dotnet test.
+func (runner *Model) SetTestRunner(mode TestRunnerMode, logger DotnetTestLogger) {
+	runner.testRunnerMode = mode
+	runner.dotnetTestLogger = logger
+}
+
+// SetDotnetPath sets the path of dotnet, dotnet is looked up in the PATH if not set.
+func (runner *Model) SetDotnetPath(pth string) {
+	runner.dotnetPth = pth
+}
+
+// UsesNunitConsole reports whether any selected nunit test project runs with nunit3-console.
+func (runner Model) UsesNunitConsole(configuration, platform string) bool {
+	for _, proj := range runner.selectedTestProjects(configuration, platform) {
+		if proj.TestFramework != constants.TestFrameworkNunitLiteTest && !runner.runsWithDotnetTest(proj) && !runner.nunit2ProjectIDs[proj.ID] {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesNunit2Console reports whether any selected nunit test project runs with the NUnit 2 nunit-console.
+func (runner Model) UsesNunit2Console(configuration, platform string) bool {
+	for _, proj := range runner.selectedTestProjects(configuration, platform) {
+		if runner.nunit2ProjectIDs[proj.ID] && !runner.runsWithDotnetTest(proj) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListsTests reports whether the tests of the named test project can be listed (with the --explore option),
+// the test projects running with dotnet test or with the NUnit 2 console can not list their tests.
+func (runner Model) ListsTests(projectName string) bool {
+	for _, proj := range runner.solution.ProjectMap {
+		if proj.Name == projectName {
+			return !runner.runsWithDotnetTest(proj) && !runner.nunit2ProjectIDs[proj.ID]
+		}
+	}
+	return false
+}
+
+// UsesNunitLite reports whether any selected test project is an NUnitLite test project, running under mono.
+func (runner Model) UsesNunitLite(configuration, platform string) bool {
+	for _, proj := range runner.selectedTestProjects(configuration, platform) {
+		if proj.TestFramework == constants.TestFrameworkNunitLiteTest {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesDotnetTest reports whether any selected nunit test project runs with dotnet test.
+func (runner Model) UsesDotnetTest(configuration, platform string) bool {
+	for _, proj := range runner.selectedTestProjects(configuration, platform) {
+		if runner.runsWithDotnetTest(proj) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetTestFilter sets the test selection of every test command.
+// NUnit 2 test projects support only the category filters.
+func (runner *Model) SetTestFilter(testFilter filter.Model) {
+	runner.testFilter = testFilter
+}
+
+// testCommandError wraps the error of a test command with its test runner and the number of the failed tests in its test result,
+// to tell the failed tests apart from the errors of the test runner by the exit code.
+func testCommandError(err error, runner outcome.TestRunner, resultLogPth string) error {
+	if err == nil {
+		return nil
+	}
+
+	failed := -1
+	if resultLogPth != "" {
+		if exist, existErr := pathutil.IsPathExists(resultLogPth); existErr == nil && exist {
+			if run, parseErr := testresult.ParseFile(resultLogPth); parseErr == nil {
+				failed = run.Failed
+			}
+		}
+	}
+
+	return outcome.TestCommandError{Err: err, Runner: runner, ResultFailed: failed}
+}
+
+// removeTestResult removes the test result of a previous run, so that a missing test result is not mistaken for the result of the run.
+func removeTestResult(pth string) error {
+	if pth == "" {
+		return nil
+	}
+	if err := os.RemoveAll(pth); err != nil {
+		return fmt.Errorf("Failed to remove previous test result, error: %s", err)
+	}
+	return nil
+}
+
+// ValidateTestFilter checks if the test filter is supported by the test runners of the selected test projects:
+// nunit-console.exe (NUnit 2) does not support the test selection language, only the category filters.
+func (runner Model) ValidateTestFilter(configuration, platform string) error {
+	if runner.testFilter.Where == "" && len(runner.testFilter.TestNamePatterns) == 0 {
+		return nil
+	}
+
+	testProjects, _ := runner.selectNunitTestProjects(configuration, platform)
+	for _, proj := range testProjects {
+		if runner.nunit2ProjectIDs[proj.ID] && !runner.runsWithDotnetTest(proj) {
+			return fmt.Errorf("project (%s) refers to NUnit 2, only the category filters are supported for NUnit 2 test projects", proj.Name)
+		}
+	}
+	return nil
+}
+
+// CustomOptions are the additional options of the test commands, by test runner.
+type CustomOptions struct {
+	NunitConsole  []string
+	Nunit2Console []string
+	NunitLite     []string
+	DotnetTest    []string
+}
+
+// SetCustomOptions sets the additional options of the test commands.
+func (runner *Model) SetCustomOptions(options CustomOptions) {
+	runner.customOptions = options
+}
@@ -0,0 +1,263 @@
+package testrunner
+
+import (
+	"fmt"
+
+	"github.com/bitrise-steplib/steps-nunit-runner/filter"
+	"github.com/bitrise-steplib/steps-nunit-runner/outcome"
+	"github.com/bitrise-tools/go-xamarin/analyzers/project"
+	"github.com/bitrise-tools/go-xamarin/analyzers/solution"
+	"github.com/bitrise-tools/go-xamarin/builder"
+	"github.com/bitrise-tools/go-xamarin/constants"
+	"github.com/bitrise-tools/go-xamarin/tools"
+	"github.com/bitrise-tools/go-xamarin/tools/buildtools"
+)
+
+// Model ...
+type Model struct {
+	solution           solution.Model
+	solutionProjectIDs []string
+	assemblyInputs     bool
+
+	buildTool buildtools.BuildTool
+
+	projectFilter   ProjectFilter
+	projectOrder    ProjectOrder
+	projectPriority []string
+
+	concurrency int
+
+	nunitConsolePth  string
+	nunit2ConsolePth string
+	nunit2ProjectIDs map[string]bool
+
+	monoPth      string
+	buildToolPth string
+
+	sdkStyleProjectIDs map[string]bool
+	testRunnerMode     TestRunnerMode
+	dotnetPth          string
+	dotnetTestLogger   DotnetTestLogger
+
+	testFilter    filter.Model
+	customOptions CustomOptions
+
+	testInput        TestInput
+	singleInvocation bool
+
+	buildScope BuildScope
+}
+
+// New ...
+func New(solutionPth string, buildTool buildtools.BuildTool) (Model, error) {
+	if err := validateSolutionPth(solutionPth); err != nil {
+		return Model{}, err
+	}
+
+	solution, err := solution.New(solutionPth, true)
+	if err != nil {
+		return Model{}, err
+	}
+
+	projectIDs, err := solutionProjectIDs(solution.Pth)
+	if err != nil {
+		return Model{}, err
+	}
+
+	sdkStyleProjectIDs := map[string]bool{}
+	nunit2ProjectIDs := map[string]bool{}
+	for projectID, proj := range solution.ProjectMap {
+		// SDK-style projects do not have a ProjectGuid, the id of the solution is used
+		if proj.ID == "" {
+			proj.ID = projectID
+			solution.ProjectMap[projectID] = proj
+		}
+
+		if sdkStyle, err := isSDKStyleNunitProject(proj.Pth); err == nil && sdkStyle {
+			sdkStyleProjectIDs[projectID] = true
+		}
+
+		if proj.TestFramework == constants.TestFrameworkNunitTest {
+			if version, err := nunitFrameworkVersion(proj.Pth); err == nil && isNunit2Version(version) {
+				nunit2ProjectIDs[projectID] = true
+			}
+		}
+	}
+
+	return Model{
+		solution:           solution,
+		solutionProjectIDs: projectIDs,
+		buildTool:          buildTool,
+		projectOrder:       ProjectOrderSolution,
+		concurrency:        1,
+		sdkStyleProjectIDs: sdkStyleProjectIDs,
+		nunit2ProjectIDs:   nunit2ProjectIDs,
+		testRunnerMode:     TestRunnerAuto,
+		dotnetTestLogger:   DotnetTestLoggerTRX,
+		testInput:          TestInputProject,
+		buildScope:         BuildScopeSolution,
+	}, nil
+}
+
+// SetProjectFilter sets the filter, which selects the test projects to run.
+func (runner *Model) SetProjectFilter(filter ProjectFilter) {
+	runner.projectFilter = filter
+}
+
+// SetToolPaths sets the paths of mono and of the build tool (msbuild or xbuild),
+// the default macOS paths of go-xamarin are used if not set.
+func (runner *Model) SetToolPaths(monoPth, buildToolPth string) {
+	runner.monoPth = monoPth
+	runner.buildToolPth = buildToolPth
+}
+
+// BuildSolution ...
+func (runner Model) BuildSolution(configuration, platform string, callback builder.BuildCommandCallback) error {
+	if runner.assemblyInputs {
+		return fmt.Errorf("No solution to build, the test assemblies are run")
+	}
+	if err := validateSolutionConfig(runner.solution, configuration, platform); err != nil {
+		return err
+	}
+
+	buildCommand, err := runner.buildSolutionCommand(configuration, platform)
+	if err != nil {
+		return fmt.Errorf("Failed to create build command, error: %s", err)
+	}
+
+	// Callback to notify the caller about next running command
+	if callback != nil {
+		callback(runner.solution.Name, "", constants.SDKUnknown, constants.TestFrameworkUnknown, buildCommand.PrintableCommand(), false)
+	}
+
+	return buildCommand.Run()
+}
+
+// RunAllNunitTestProjects runs every nunit test project of the solution.
+// A failing test project does not stop the run: the outcome is decided from the results of every test project,
+// the most severe error of the test projects is returned.
+func (runner Model) RunAllNunitTestProjects(configuration, platform string, callback builder.BuildCommandCallback, prepareCallback builder.PrepareCommandCallback) ([]string, error) {
+	if err := runner.validateConfig(configuration, platform); err != nil {
+		return nil, err
+	}
+
+	buildableProjects, warnings := runner.buildableNunitTestProjects(configuration, platform)
+	if len(buildableProjects) == 0 {
+		return warnings, fmt.Errorf("No project to build found")
+	}
+
+	return runner.runNunitTestProjects(configuration, platform, buildableProjects, warnings, callback, prepareCallback)
+}
+
+// RunNunitTestProject runs the nunit test project with the given name.
+func (runner Model) RunNunitTestProject(configuration, platform, projectName string, callback builder.BuildCommandCallback, prepareCallback builder.PrepareCommandCallback) ([]string, error) {
+	return runner.RunNunitTestProjects(configuration, platform, []string{projectName}, callback, prepareCallback)
+}
+
+// RunNunitTestProjects runs the nunit test projects with the given names.
+func (runner Model) RunNunitTestProjects(configuration, platform string, projectNames []string, callback builder.BuildCommandCallback, prepareCallback builder.PrepareCommandCallback) ([]string, error) {
+	if err := runner.validateConfig(configuration, platform); err != nil {
+		return nil, err
+	}
+
+	buildableProjects, warnings := runner.buildableNunitTestProjects(configuration, platform)
+
+	testProjects := []project.Model{}
+	for _, projectName := range projectNames {
+		found := false
+		for _, proj := range buildableProjects {
+			if proj.Name == projectName {
+				testProjects = append(testProjects, proj)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return warnings, fmt.Errorf("Test project (%s) not found", projectName)
+		}
+	}
+
+	return runner.runNunitTestProjects(configuration, platform, testProjects, warnings, callback, prepareCallback)
+}
+
+// BuildAndRunAllNunitTestProjects ...
+func (runner Model) BuildAndRunAllNunitTestProjects(configuration, platform string, callback builder.BuildCommandCallback, prepareCallback builder.PrepareCommandCallback) ([]string, error) {
+	warnings, err := runner.Build(configuration, platform, callback)
+	if err != nil {
+		return warnings, err
+	}
+
+	warns, err := runner.RunAllNunitTestProjects(configuration, platform, callback, prepareCallback)
+	return append(warnings, warns...), err
+}
+
+func (runner Model) runNunitTestProjects(configuration, platform string, testProjects []project.Model, warnings []string, callback builder.BuildCommandCallback, prepareCallback builder.PrepareCommandCallback) ([]string, error) {
+	commands, warns, err := runner.prepareNunitTestProjectCommands(configuration, platform, testProjects, prepareCallback)
+	warnings = append(warnings, warns...)
+	if err != nil {
+		return warnings, err
+	}
+
+	if runner.combinesTestAssemblies() {
+		testProjects, commands, err = runner.combineNunitConsoleCommands(testProjects, commands)
+		if err != nil {
+			return warnings, err
+		}
+	}
+
+	if runner.concurrency > 1 && len(testProjects) > 1 {
+		return warnings, runner.runNunitTestProjectsInParallel(testProjects, commands, callback)
+	}
+
+	var runErr error
+
+	for i, testProj := range testProjects {
+		// Callback to notify the caller about next running command
+		if callback != nil {
+			callback(runner.solution.Name, testProj.Name, constants.SDKUnknown, constants.TestFrameworkNunitTest, commands[i].PrintableCommand(), false)
+		}
+
+		if err := commands[i].Run(); err != nil {
+			runErr = moreSevereError(runErr, err)
+		}
+	}
+
+	return warnings, runErr
+}
+
+func (runner Model) prepareNunitTestProjectCommands(configuration, platform string, testProjects []project.Model, prepareCallback builder.PrepareCommandCallback) ([]TestCommand, []string, error) {
+	commands := []TestCommand{}
+	warnings := []string{}
+
+	for _, testProj := range testProjects {
+		buildCommand, warns, err := runner.buildTestProjectCommand(configuration, platform, testProj)
+		warnings = append(warnings, warns...)
+		if err != nil {
+			return nil, warnings, fmt.Errorf("Failed to create build command, error: %s", err)
+		}
+
+		// Callback to let the caller to modify the command
+		if prepareCallback != nil {
+			editabeCommand := tools.Editable(buildCommand)
+			prepareCallback(runner.solution.Name, testProj.Name, constants.SDKUnknown, constants.TestFrameworkNunitTest, &editabeCommand)
+		}
+
+		commands = append(commands, buildCommand)
+	}
+
+	return commands, warnings, nil
+}
+
+// moreSevereError prefers the errors of the test runner (like an invalid assembly) over the errors of failing tests.
+func moreSevereError(current, next error) error {
+	if current == nil {
+		return next
+	}
+
+	if outcome.RunErrorReason(current, outcome.StageTest) == outcome.FailureReasonTestFailures &&
+		outcome.RunErrorReason(next, outcome.StageTest) != outcome.FailureReasonTestFailures {
+		return next
+	}
+
+	return current
+}
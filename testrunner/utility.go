@@ -0,0 +1,43 @@
+package testrunner
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/bitrise-io/go-utils/pathutil"
+	"github.com/bitrise-tools/go-xamarin/analyzers/solution"
+	"github.com/bitrise-tools/go-xamarin/constants"
+	"github.com/bitrise-tools/go-xamarin/utility"
+)
+
+func validateSolutionPth(pth string) error {
+	ext := filepath.Ext(pth)
+	if ext != constants.SolutionExt {
+		return fmt.Errorf("path is not a solution file path: %s", pth)
+	}
+	if exist, err := pathutil.IsPathExists(pth); err != nil {
+		return err
+	} else if !exist {
+		return fmt.Errorf("solution not exist at: %s", pth)
+	}
+	return nil
+}
+
+// validateConfig validates the solution config, the test assemblies run without a solution do not have configs.
+func (runner Model) validateConfig(configuration, platform string) error {
+	if runner.assemblyInputs {
+		return nil
+	}
+	return validateSolutionConfig(runner.solution, configuration, platform)
+}
+
+func validateSolutionConfig(solution solution.Model, configuration, platform string) error {
+	config := utility.ToConfig(configuration, platform)
+	if _, ok := solution.ConfigMap[config]; !ok {
+		configList := solution.ConfigList()
+		sort.Strings(configList)
+		return fmt.Errorf("invalid solution config, available: %v", configList)
+	}
+	return nil
+}
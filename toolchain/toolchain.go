@@ -0,0 +1,125 @@
+package toolchain
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/command"
+	"github.com/bitrise-io/go-utils/pathutil"
+)
+
+// Tool names
+const (
+	Mono    = "mono"
+	Msbuild = "msbuild"
+	Xbuild  = "xbuild"
+	Dotnet  = "dotnet"
+)
+
+const macMonoCommandsDir = "/Library/Frameworks/Mono.framework/Versions/Current/Commands"
+
+// standard install locations of the tools, by operating system
+var standardDirs = map[string][]string{
+	"darwin": {macMonoCommandsDir, "/usr/local/bin", "/opt/homebrew/bin", "/usr/local/share/dotnet"},
+	"linux":  {"/usr/bin", "/usr/local/bin", "/opt/mono/bin", "/snap/bin", "/usr/share/dotnet", "/usr/lib/dotnet"},
+}
+
+// version probe arguments of the tools
+var versionArgs = map[string][]string{
+	Mono:    {"--version"},
+	Msbuild: {"-version", "-nologo"},
+	Xbuild:  {"/version"},
+	Dotnet:  {"--version"},
+}
+
+var versionPattern = regexp.MustCompile(`[0-9]+\.[0-9]+(\.[0-9]+)*`)
+
+// Tool is a resolved tool, with its version and the place it was found at.
+type Tool struct {
+	Name    string
+	Pth     string
+	Version string
+	Source  string
+}
+
+// String ...
+func (tool Tool) String() string {
+	return fmt.Sprintf("%s %s (%s, from %s)", tool.Name, tool.Version, tool.Pth, tool.Source)
+}
+
+// Resolve finds the named tool (mono, msbuild, xbuild or dotnet) at the explicit path (if set),
+// or in the PATH, or in the standard install locations of macOS and Linux.
+// A candidate is accepted if its version can be probed.
+func Resolve(name, explicitPth string) (Tool, error) {
+	if explicitPth != "" {
+		version, err := probeVersion(name, explicitPth)
+		if err != nil {
+			return Tool{}, fmt.Errorf("invalid %s (%s), error: %s", name, explicitPth, err)
+		}
+		return Tool{Name: name, Pth: explicitPth, Version: version, Source: "input"}, nil
+	}
+
+	type candidate struct {
+		pth    string
+		source string
+	}
+	candidates := []candidate{}
+
+	if pth, err := exec.LookPath(name); err == nil {
+		candidates = append(candidates, candidate{pth: pth, source: "PATH"})
+	}
+
+	// the locations of the current operating system first
+	dirs := append([]string{}, standardDirs[runtime.GOOS]...)
+	for _, goos := range []string{"darwin", "linux"} {
+		if goos != runtime.GOOS {
+			dirs = append(dirs, standardDirs[goos]...)
+		}
+	}
+	for _, dir := range dirs {
+		candidates = append(candidates, candidate{pth: dir + "/" + name, source: "standard location"})
+	}
+
+	probeErrors := []string{}
+	probed := map[string]bool{}
+	for _, c := range candidates {
+		if probed[c.pth] {
+			continue
+		}
+		probed[c.pth] = true
+
+		if exist, err := pathutil.IsPathExists(c.pth); err != nil || !exist {
+			continue
+		}
+
+		version, err := probeVersion(name, c.pth)
+		if err != nil {
+			probeErrors = append(probeErrors, fmt.Sprintf("%s: %s", c.pth, err))
+			continue
+		}
+
+		return Tool{Name: name, Pth: c.pth, Version: version, Source: c.source}, nil
+	}
+
+	if len(probeErrors) > 0 {
+		return Tool{}, fmt.Errorf("no working %s found, %s", name, strings.Join(probeErrors, ", "))
+	}
+	return Tool{}, fmt.Errorf("%s not found in the PATH and in the standard locations", name)
+}
+
+func probeVersion(name, pth string) (string, error) {
+	cmd := command.New(pth, versionArgs[name]...)
+	out, err := cmd.RunAndReturnTrimmedCombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("version probe (%s) failed, output: %s, error: %s", cmd.PrintableCommandArgs(), out, err)
+	}
+
+	version := versionPattern.FindString(out)
+	if version == "" {
+		return "", fmt.Errorf("version not found in the output of %s: %s", cmd.PrintableCommandArgs(), out)
+	}
+	return version, nil
+}